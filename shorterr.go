@@ -1,14 +1,226 @@
 // Package shorterr is an implementation of a short-circuit error handling
 // inspired by the ? operator in Rust.
+//
+// Short-circuits are implemented with panic and recover. While this adds no
+// noticeable cost as long as no error occurs, a short-circuit is an order of
+// magnitude slower than returning an error. Performance critical code with
+// frequent errors can use the Err method of the Result types instead, which
+// never short-circuits:
+//
+//	r := se.Do(strconv.Atoi(s))
+//	if err := r.Err(); err != nil {
+//		...
+//	}
 package shorterr
 
 import (
+	"cmp"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
-type shortCircuitError error
+// shortCircuitError marks a panic as a short-circuit, so that other panics,
+// even if their value is an error, are not intercepted by PassTo. Since it is
+// unexported, other packages can neither create nor match it, except as a
+// plain error.
+type shortCircuitError struct{ error }
+
+// Error is only called if the short-circuit wasn't intercepted, e.g. when
+// the runtime prints the value of an unrecovered panic.
+func (e shortCircuitError) Error() string {
+	return "shorterr: short-circuit without PassTo: " + e.error.Error()
+}
+
+// Unwrap allows a foreign recover to inspect the error with errors.Is and
+// errors.As.
+func (e shortCircuitError) Unwrap() error {
+	return e.error
+}
+
+// OnShortCircuit, if not nil, is called with the final error of every
+// short-circuit. It runs on the short-circuiting goroutine right before the
+// stack is unwound, so it must not short-circuit itself.
+var OnShortCircuit func(error)
+
+// OnShortCircuitSlog, if not nil, logs every short-circuit with the final error
+// as "error" attribute at the level ShortCircuitSlogLevel.
+var OnShortCircuitSlog *slog.Logger
+
+// ShortCircuitSlogLevel is the level used by OnShortCircuitSlog.
+var ShortCircuitSlogLevel = slog.LevelDebug
+
+// CaptureStack enables recording of the call stack at the point of every
+// short-circuit. The intercepted error then provides it with a
+// StackTrace() []uintptr method and prints it when formatted with %+v.
+var CaptureStack = false
+
+// IncludeCaller enables prefixing the error of every short-circuit with the
+// file and line of the short-circuiting call.
+var IncludeCaller = false
+
+// Metrics counts short-circuits.
+type Metrics interface {
+	// IncShortCircuit is called for every short-circuit with the location of
+	// the short-circuiting call as label, like "example.com/pkg.Foo:42".
+	IncShortCircuit(label string)
+}
+
+var metrics Metrics
+
+// SetMetrics sets m to count all short-circuits. A nil m disables counting.
+func SetMetrics(m Metrics) {
+	metrics = m
+}
+
+type mapper struct {
+	match func(error) bool
+	map_  func(error) error
+}
+
+var mappers []*mapper
+
+// RegisterMapper registers map_ to replace the error of every short-circuit
+// that satisfies match, and returns a function that removes it again. Of all
+// registered mappers, only the first one that matches is applied, in the order
+// of registration. map_ must not return nil. Like the other package settings,
+// mappers must not be registered or removed concurrently with short-circuits,
+// e.g. only during initialization:
+//
+//	se.RegisterMapper(
+//		func(err error) bool { return errors.Is(err, sql.ErrNoRows) },
+//		func(err error) error { return ErrNotFound })
+func RegisterMapper(match func(error) bool, map_ func(error) error) (unregister func()) {
+	m := &mapper{match, map_}
+	mappers = append(mappers, m)
+	return func() {
+		for i := range mappers {
+			if mappers[i] == m {
+				mappers = append(mappers[:i:i], mappers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// shortCircuit interrupts the execution of the current function with err.
+func shortCircuit(err error) {
+	for _, m := range mappers {
+		if m.match(err) {
+			if err = m.map_(err); err == nil {
+				panic(errors.New("shorterr: mapper returned nil"))
+			}
+			break
+		}
+	}
+	if IncludeCaller {
+		err = wrap(err, caller())
+	}
+	if CaptureStack {
+		err = &stackError{err, callers()}
+	}
+	if OnShortCircuit != nil {
+		OnShortCircuit(err)
+	}
+	if metrics != nil {
+		metrics.IncShortCircuit(callerFunc())
+	}
+	if OnShortCircuitSlog != nil {
+		OnShortCircuitSlog.Log(context.Background(), ShortCircuitSlogLevel,
+			"short-circuit", slog.Any("error", err))
+	}
+	panic(shortCircuitError{err})
+}
+
+// caller returns the location of the first function outside of this package
+// on the call stack as file name and line.
+func caller() string {
+	pcs := callers()
+	if len(pcs) == 0 {
+		return "unknown"
+	}
+	f, _ := runtime.CallersFrames(pcs).Next()
+	return fmt.Sprintf("%s:%d", filepath.Base(f.File), f.Line)
+}
+
+// callerFunc is like caller, but with the fully qualified function name instead
+// of the file name, which unlike the latter is unique across packages.
+func callerFunc() string {
+	pcs := callers()
+	if len(pcs) == 0 {
+		return "unknown"
+	}
+	f, _ := runtime.CallersFrames(pcs).Next()
+	return fmt.Sprintf("%s:%d", f.Function, f.Line)
+}
+
+type stackError struct {
+	err   error
+	stack []uintptr
+}
+
+func (e *stackError) Error() string {
+	return e.err.Error()
+}
+
+func (e *stackError) Unwrap() error {
+	return e.err
+}
+
+func (e *stackError) StackTrace() []uintptr {
+	return e.stack
+}
+
+func (e *stackError) Format(s fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && s.Flag('+'):
+		io.WriteString(s, e.Error())
+		frames := runtime.CallersFrames(e.stack)
+		for more := true; more; {
+			var f runtime.Frame
+			f, more = frames.Next()
+			fmt.Fprintf(s, "\n%s\n\t%s:%d", f.Function, f.File, f.Line)
+		}
+	case verb == 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	default:
+		io.WriteString(s, e.Error())
+	}
+}
+
+const pkgPrefix = "github.com/ansiwen/shorterr."
+
+// callers returns the call stack starting at the first function outside of
+// this package.
+func callers() []uintptr {
+	pcs := make([]uintptr, 64)
+	pcs = pcs[:runtime.Callers(2, pcs)]
+	for i := range pcs {
+		// An inlined call of this package belongs to the outermost function.
+		frames := runtime.CallersFrames(pcs[i : i+1])
+		var f runtime.Frame
+		for more := true; more; {
+			f, more = frames.Next()
+		}
+		if !strings.HasPrefix(f.Function, pkgPrefix) {
+			return pcs[i:]
+		}
+	}
+	return nil
+}
 
 // PassTo stores the intercepted error in the variable err is pointing to. It
 // must be installed with defer in the current function before the other
@@ -19,160 +231,1852 @@ type shortCircuitError error
 //	func Foo() (err error) {
 //		defer se.PassTo(&err)
 //	...
+//
+// A short-circuit is always intercepted by the innermost PassTo on the call
+// stack. Since a short-circuit can only unwind through functions that are
+// still executing, a nested function using PassTo never intercepts a
+// short-circuit of its caller.
 func PassTo(err *error) {
-	if v := recover(); v != nil {
-		if e, ok := v.(shortCircuitError); ok {
+	if e := intercept(recover()); e != nil {
+		*err = e
+	}
+}
+
+// PassToWith is like PassTo, but the intercepted error is passed through
+// transform before it is stored in the variable err is pointing to:
+//
+//	func Foo() (err error) {
+//		defer se.PassToWith(&err, addStack)
+//	...
+func PassToWith(err *error, transform func(error) error) {
+	if e := intercept(recover()); e != nil {
+		*err = transform(e)
+	}
+}
+
+// PassTof is like PassTo, but the intercepted error is wrapped with a message
+// formatted according to format and args before it is stored:
+//
+//	func handleRequest(id int) (err error) {
+//		defer se.PassTof(&err, "handleRequest(%d)", id)
+//	...
+func PassTof(err *error, format string, args ...any) {
+	if e := intercept(recover()); e != nil {
+		*err = wrapf(e, format, args...)
+	}
+}
+
+// PassToJoin is like PassTo, but joins the intercepted error with an error that
+// is already stored in the variable err is pointing to by errors.Join instead
+// of overwriting it. If no error is stored yet, the intercepted error is stored
+// as it is.
+func PassToJoin(err *error) {
+	if e := intercept(recover()); e != nil {
+		if *err == nil {
 			*err = e
 		} else {
-			panic(v)
+			*err = errors.Join(*err, e)
+		}
+	}
+}
+
+// PassToFunc is like PassTo, but afterwards calls done with the resulting
+// error in the variable err is pointing to and whether it was intercepted from
+// a short-circuit. done is called on every return, but not for other panics,
+// which are passed on unchanged.
+func PassToFunc(err *error, done func(err error, shortCircuited bool)) {
+	e := intercept(recover())
+	if e != nil {
+		*err = e
+	}
+	done(*err, e != nil)
+}
+
+// PassToChan is like PassTo, but sends the intercepted error on ch instead of
+// storing it. Nothing is sent if the function returns without short-circuiting.
+// To send on every return, PassToFunc can be used instead:
+//
+//	var err error
+//	defer se.PassToFunc(&err, func(err error, _ bool) { ch <- err })
+func PassToChan(ch chan<- error) {
+	if e := intercept(recover()); e != nil {
+		ch <- e
+	}
+}
+
+// PassToAll is like PassTo, but additionally calls cleanups in reverse order,
+// regardless of whether the function short-circuited. It is a lightweight
+// alternative to Scope. Like PassTo, it must be installed before the first
+// short-circuit, and since the cleanups are evaluated when the defer statement
+// runs, they have to close over variables that are assigned later:
+//
+//	func Foo() (err error) {
+//		var f *os.File
+//		defer se.PassToAll(&err, func() {
+//			if f != nil {
+//				f.Close()
+//			}
+//		})
+//		f = se.Try(os.Open("data.json"))
+//	...
+func PassToAll(err *error, cleanups ...func()) {
+	defer func() {
+		for i := len(cleanups) - 1; i >= 0; i-- {
+			cleanups[i]()
+		}
+	}()
+	if e := intercept(recover()); e != nil {
+		*err = e
+	}
+}
+
+// PassToClean is like PassTo, but additionally calls zero if an error was
+// intercepted. zero is meant to reset the other named results, so that callers
+// never see partial results together with an error:
+//
+//	func Foo() (a, b int, err error) {
+//		defer se.PassToClean(&err, func() { a, b = 0, 0 })
+//	...
+func PassToClean(err *error, zero func()) {
+	if e := intercept(recover()); e != nil {
+		*err = e
+		zero()
+	}
+}
+
+// Catch is like PassTo, but if the intercepted error matches E according to
+// errors.As, handler is called with it instead and the variable err is pointing
+// to is cleared. The handler may still set it, e.g. through a closure:
+//
+//	func Foo() (err error) {
+//		defer se.Catch(&err, func(e *fs.PathError) { ... })
+//	...
+func Catch[E error](err *error, handler func(E)) {
+	if e := intercept(recover()); e != nil {
+		var target E
+		if errors.As(e, &target) {
+			*err = nil
+			handler(target)
+		} else {
+			*err = e
 		}
 	}
 }
 
+// RecoverAll is like PassTo, but additionally stores any other panic as an
+// error in the variable err is pointing to instead of re-panicking. It is meant
+// for boundaries like goroutines, where a panic must never escape:
+//
+//	errc := make(chan error, 1)
+//	go func() {
+//		var err error
+//		defer func() { errc <- err }()
+//		defer se.RecoverAll(&err)
+//	...
+//
+// Go and Group do this for a function returning an error.
+// If the panic value is an error, it is wrapped, so that e.g. a runtime.Error
+// can still be detected with errors.As.
+func RecoverAll(err *error) {
+	if v := recover(); v != nil {
+		*err = panicError(v)
+	}
+}
+
+// panicError converts the non-nil panic value v to an error like RecoverAll.
+func panicError(v any) error {
+	switch e := v.(type) {
+	case shortCircuitError:
+		return e.error
+	case error:
+		return fmt.Errorf("panic: %w", e)
+	default:
+		return fmt.Errorf("panic: %v", v)
+	}
+}
+
+// Wrap wraps the error in the variable err is pointing to with prefix, if it
+// is not nil. Since deferred functions run in reverse order, it must be
+// installed with defer before PassTo in order to also wrap intercepted errors:
+//
+//	func Foo() (err error) {
+//		defer se.Wrap(&err, "foo")
+//		defer se.PassTo(&err)
+//	...
+func Wrap(err *error, prefix string) {
+	if *err != nil {
+		*err = wrap(*err, prefix)
+	}
+}
+
+// OnError calls f with the error in the variable err is pointing to, if it is
+// not nil. Like Wrap, it must be installed with defer before PassTo in order to
+// see intercepted errors:
+//
+//	func Foo() (err error) {
+//		tx := se.Try(db.Begin())
+//		defer se.OnError(&err, func(error) { tx.Rollback() })
+//		defer se.PassTo(&err)
+//	...
+func OnError(err *error, f func(error)) {
+	if *err != nil {
+		f(*err)
+	}
+}
+
+// intercept returns the error of the recovered value v if it is a
+// short-circuit. Any other non-nil value is re-panicked.
+func intercept(v any) error {
+	if v == nil {
+		return nil
+	}
+	if e, ok := v.(shortCircuitError); ok {
+		return e.error
+	}
+	panic(v)
+}
+
+// WrapFormat is the format used to wrap an error with a message. It must
+// contain exactly one %s verb for the message followed by one %w verb for the
+// error.
+var WrapFormat = "%s: %w"
+
+func wrap(err error, msg string) error {
+	return fmt.Errorf(WrapFormat, msg, err)
+}
+
+// wrapf is like wrap, but formats the message according to format and args.
+// If format wraps errors with %w, they remain reachable next to err, at the
+// cost of errors.Unwrap no longer returning err.
+func wrapf(err error, format string, args ...any) error {
+	if !strings.Contains(format, "%w") {
+		return wrap(err, fmt.Sprintf(format, args...))
+	}
+	return fmt.Errorf(strings.Replace(WrapFormat, "%s", "%w", 1), fmt.Errorf(format, args...), err)
+}
+
 // Check short-circuits the execution of the current function if the error is
 // not nil. If the optional msg is provided, the err is wrapped with msg. PassTo
 // must be installed with defer before.
 func Check(err error, msg ...string) {
 	if err != nil {
-		msg := strings.Join(msg, " ")
-		if len(msg) > 0 {
-			err = fmt.Errorf("%s: %w", msg, err)
-		}
-		panic(shortCircuitError(err))
+		shortCircuit(wrapMsg(err, msg))
 	}
 }
 
-// Assert short-circuits the execution of the current function if ok is false
-// and returns msg as an error. PassTo must be installed with defer before.
-func Assert(ok bool, msg string) {
-	if !ok {
-		panic(shortCircuitError(errors.New(msg)))
+// wrapMsg wraps err with msg joined by spaces, unless it is empty.
+func wrapMsg(err error, msg []string) error {
+	if msg := strings.Join(msg, " "); len(msg) > 0 {
+		return wrap(err, msg)
 	}
+	return err
 }
 
-// Try is a wrapper for functions that return a value and an error. It
-// short-circuits the execution of the current function if the error is not nil.
-// Otherwise it only returns the result value. PassTo must be installed with
-// defer before.
-func Try[A any](a A, err error) A {
-	Check(err)
-	return a
+// Checkf is like Check, but wraps the error with a message formatted according
+// to format and args. As with fmt.Errorf, format may wrap further errors with
+// %w. PassTo must be installed with defer before.
+func Checkf(err error, format string, args ...any) {
+	if err != nil {
+		shortCircuit(wrapf(err, format, args...))
+	}
 }
 
-// Try2 is Try for functions with 2-ary results.
-func Try2[A, B any](a A, b B, err error) (A, B) {
-	Check(err)
-	return a, b
+// CheckOpaque is like Check, but the wrapped error only keeps the message of
+// err, so that err can't be found with errors.Is or errors.As. This prevents
+// leaking internal error types across API boundaries. PassTo must be installed
+// with defer before.
+func CheckOpaque(err error, msg string) {
+	if err != nil {
+		shortCircuit(fmt.Errorf(strings.Replace(WrapFormat, "%w", "%v", 1), msg, err))
+	}
 }
 
-// Try3 is Try for functions with 3-ary results.
-func Try3[A, B, C any](a A, b B, c C, err error) (A, B, C) {
-	Check(err)
-	return a, b, c
+// CheckWith is like Check, but the parts of the message are joined with sep
+// instead of a space:
+//
+//	se.CheckWith(err, " > ", "load", "parse") // "load > parse: <err>"
+//
+// PassTo must be installed with defer before.
+func CheckWith(err error, sep string, parts ...string) {
+	if err != nil {
+		Check(err, strings.Join(parts, sep))
+	}
 }
 
-// Try4 is Try for functions with 4-ary results.
-func Try4[A, B, C, D any](a A, b B, c C, d D, err error) (A, B, C, D) {
-	Check(err)
-	return a, b, c, d
+// CheckLazy is like Check, but the message is only built by calling msgFn if
+// err is not nil. PassTo must be installed with defer before.
+func CheckLazy(err error, msgFn func() string) {
+	if err != nil {
+		Check(err, msgFn())
+	}
 }
 
-// Try5 is Try for functions with 5-ary results.
-func Try5[A, B, C, D, E any](a A, b B, c C, d D, e E, err error) (A, B, C, D, E) {
-	Check(err)
-	return a, b, c, d, e
+// CheckAll short-circuits the execution of the current function with the first
+// of errs that is not nil. Note that, as with any function call, all arguments
+// are evaluated before CheckAll is called. PassTo must be installed with defer
+// before.
+func CheckAll(errs ...error) {
+	for _, err := range errs {
+		Check(err)
+	}
 }
 
-type Result[A any] struct {
-	a   A
-	err error
+// CheckJoin is like CheckAll, but short-circuits with all errs that are not
+// nil joined by errors.Join. PassTo must be installed with defer before.
+func CheckJoin(errs ...error) {
+	Check(errors.Join(errs...))
 }
 
-type Result2[A, B any] struct {
-	a   A
-	b   B
-	err error
+// CheckIf short-circuits the execution of the current function with sentinel
+// if cond is true:
+//
+//	se.CheckIf(len(rows) == 0, ErrNotFound)
+//
+// sentinel must not be nil. PassTo must be installed with defer before.
+func CheckIf(cond bool, sentinel error) {
+	if sentinel == nil {
+		panic(errors.New("shorterr: CheckIf called with nil sentinel"))
+	}
+	if cond {
+		shortCircuit(sentinel)
+	}
 }
 
-type Result3[A, B, C any] struct {
-	a   A
-	b   B
-	c   C
-	err error
+// CheckUnless is like Check, but doesn't short-circuit if err matches any of
+// targets according to errors.Is:
+//
+//	se.CheckUnless(err, io.EOF)
+//
+// PassTo must be installed with defer before.
+func CheckUnless(err error, targets ...error) {
+	for _, target := range targets {
+		if errors.Is(err, target) {
+			return
+		}
+	}
+	Check(err)
 }
 
-type Result4[A, B, C, D any] struct {
-	a   A
-	b   B
-	c   C
-	d   D
-	err error
+// TraceKey is the context key used by CheckCtx to look up a trace or request
+// id.
+var TraceKey any
+
+// CheckCtx is like Check, but if ctx carries a value for TraceKey, it is
+// prepended in brackets to the message, e.g. "[id] msg: <err>". PassTo must be
+// installed with defer before.
+func CheckCtx(ctx context.Context, err error, msg ...string) {
+	if err != nil {
+		if TraceKey != nil {
+			if id := ctx.Value(TraceKey); id != nil {
+				msg = append([]string{fmt.Sprintf("[%v]", id)}, msg...)
+			}
+		}
+		Check(err, msg...)
+	}
 }
 
-type Result5[A, B, C, D, E any] struct {
-	a   A
-	b   B
-	c   C
-	d   D
-	e   E
-	err error
+// CheckContext short-circuits the execution of the current function with the
+// error of ctx if it is done. PassTo must be installed with defer before.
+func CheckContext(ctx context.Context) {
+	Check(ctx.Err())
 }
 
-// Do is an alternative to Try that allows to wrap the short-circuit error with
-// a description by appending the Or() method.
-func Do[A any](a A, err error) *Result[A] {
-	return &Result[A]{a, err}
+// AssertNoErr is equivalent to Check, but expresses that err is expected to be
+// nil. PassTo must be installed with defer before.
+func AssertNoErr(err error, msg ...string) {
+	Check(err, msg...)
 }
 
-// Do2 is Do for 2-ary results.
-func Do2[A, B any](a A, b B, err error) *Result2[A, B] {
-	return &Result2[A, B]{a, b, err}
+// Assert short-circuits the execution of the current function if ok is false
+// and returns msg as an error. PassTo must be installed with defer before.
+func Assert(ok bool, msg string) {
+	if !ok {
+		shortCircuit(errors.New(msg))
+	}
 }
 
-// Do3 is Do for 3-ary results.
-func Do3[A, B, C any](a A, b B, c C, err error) *Result3[A, B, C] {
-	return &Result3[A, B, C]{a, b, c, err}
+// Assertf is like Assert, but the error message is formatted according to
+// format and args. PassTo must be installed with defer before.
+func Assertf(ok bool, format string, args ...any) {
+	if !ok {
+		shortCircuit(fmt.Errorf(format, args...))
+	}
 }
 
-// Do4 is Do for 4-ary results.
-func Do4[A, B, C, D any](a A, b B, c C, d D, err error) *Result4[A, B, C, D] {
-	return &Result4[A, B, C, D]{a, b, c, d, err}
+// AssertLazy is like Assert, but the message is only built by calling msgFn if
+// ok is false. PassTo must be installed with defer before.
+func AssertLazy(ok bool, msgFn func() string) {
+	if !ok {
+		shortCircuit(errors.New(msgFn()))
+	}
 }
 
-// Do5 is Do for 5-ary results.
-func Do5[A, B, C, D, E any](a A, b B, c C, d D, e E, err error) *Result5[A, B, C, D, E] {
-	return &Result5[A, B, C, D, E]{a, b, c, d, e, err}
+// AssertEqual short-circuits the execution of the current function if got is
+// not equal to want and returns msg together with both values as an error.
+// PassTo must be installed with defer before.
+func AssertEqual[T comparable](got, want T, msg string) {
+	Assertf(got == want, "%s: got %v, want %v", msg, got, want)
 }
 
-// Or returns only the result value of the function called by Do if its returned
-// error is nil. Otherwise it wraps the error with msg and short-circuits the
-// execution of the current function. PassTo must be installed with
-// defer before.
-func (r *Result[A]) Or(msg string) A {
-	Check(r.err, msg)
-	return r.a
+// AssertNotEqual short-circuits the execution of the current function if got
+// is equal to other and returns msg together with the value as an error.
+// PassTo must be installed with defer before.
+func AssertNotEqual[T comparable](got, other T, msg string) {
+	Assertf(got != other, "%s: got %v, want anything else", msg, got)
 }
 
-// Or for 2-ary results.
-func (r *Result2[A, B]) Or(msg string) (A, B) {
-	Check(r.err, msg)
-	return r.a, r.b
+// AssertGreater short-circuits the execution of the current function if v is
+// not greater than bound and returns msg together with both values as an
+// error. PassTo must be installed with defer before.
+func AssertGreater[T cmp.Ordered](v, bound T, msg string) {
+	Assertf(v > bound, "%s: got %v, want greater than %v", msg, v, bound)
 }
 
-// Or for 3-ary results.
-func (r *Result3[A, B, C]) Or(msg string) (A, B, C) {
-	Check(r.err, msg)
-	return r.a, r.b, r.c
+// AssertLess short-circuits the execution of the current function if v is not
+// less than bound and returns msg together with both values as an error.
+// PassTo must be installed with defer before.
+func AssertLess[T cmp.Ordered](v, bound T, msg string) {
+	Assertf(v < bound, "%s: got %v, want less than %v", msg, v, bound)
 }
 
-// Or for 4-ary results.
-func (r *Result4[A, B, C, D]) Or(msg string) (A, B, C, D) {
-	Check(r.err, msg)
-	return r.a, r.b, r.c, r.d
+// AssertInRange short-circuits the execution of the current function if v is
+// outside of the inclusive range [lo, hi] and returns msg together with the
+// value and the bounds as an error. PassTo must be installed with defer before.
+func AssertInRange[T cmp.Ordered](v, lo, hi T, msg string) {
+	Assertf(lo <= v && v <= hi, "%s: got %v, want in range [%v, %v]", msg, v, lo, hi)
 }
 
-// Or for 5-ary results.
+// AssertNotEmpty short-circuits the execution of the current function if s is
+// empty and returns msg as an error. PassTo must be installed with defer
+// before.
+func AssertNotEmpty[T any](s []T, msg string) {
+	Assert(len(s) > 0, msg)
+}
+
+// AssertNotBlank short-circuits the execution of the current function if s is
+// empty or contains only white space and returns msg as an error. PassTo must
+// be installed with defer before.
+func AssertNotBlank(s, msg string) {
+	Assert(strings.TrimSpace(s) != "", msg)
+}
+
+// AssertNotNil short-circuits the execution of the current function if v is nil
+// and returns msg as an error. Unlike a plain comparison with nil, it also
+// detects nil pointers, maps, slices, channels and functions stored in v.
+// PassTo must be installed with defer before.
+func AssertNotNil(v any, msg string) {
+	Assert(!isNil(v), msg)
+}
+
+func isNil(v any) bool {
+	if v == nil {
+		return true
+	}
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Chan, reflect.Func,
+		reflect.Interface, reflect.UnsafePointer:
+		return rv.IsNil()
+	}
+	return false
+}
+
+// Try is a wrapper for functions that return a value and an error. It
+// short-circuits the execution of the current function if the error is not nil.
+// Otherwise it only returns the result value. PassTo must be installed with
+// defer before.
+func Try[A any](a A, err error) A {
+	Check(err)
+	return a
+}
+
+// TryMsg is like Try, but wraps the error with msg, like Do(a, err).Or(msg)
+// without creating a Result. PassTo must be installed with defer before.
+func TryMsg[A any](a A, err error, msg string) A {
+	Check(err, msg)
+	return a
+}
+
+// TryMap returns a wrapper that is like Try, but additionally transforms the
+// result value with f. Since Go doesn't allow to pass further arguments along
+// with multiple return values, f is passed first:
+//
+//	name := se.TryMap(strings.ToUpper)(os.Hostname())
+//
+// PassTo must be installed with defer before.
+func TryMap[A, R any](f func(A) R) func(A, error) R {
+	return func(a A, err error) R {
+		Check(err)
+		return f(a)
+	}
+}
+
+// TryR is like Try for functions with error-first signatures, that return the
+// error before the value. PassTo must be installed with defer before.
+func TryR[A any](err error, a A) A {
+	Check(err)
+	return a
+}
+
+// TryR2 is TryR for functions with 2-ary results.
+func TryR2[A, B any](err error, a A, b B) (A, B) {
+	Check(err)
+	return a, b
+}
+
+// TryR3 is TryR for functions with 3-ary results.
+func TryR3[A, B, C any](err error, a A, b B, c C) (A, B, C) {
+	Check(err)
+	return a, b, c
+}
+
+// TryCast returns v asserted to type T. If v is not a T, it short-circuits the
+// execution of the current function with msg and the dynamic type of v as an
+// error. PassTo must be installed with defer before.
+func TryCast[T any](v any, msg string) T {
+	t, ok := v.(T)
+	Assertf(ok, "%s: got %T", msg, v)
+	return t
+}
+
+// AssertType is like TryCast, but only asserts that v is a T without returning
+// it. PassTo must be installed with defer before.
+func AssertType[T any](v any, msg string) {
+	TryCast[T](v, msg)
+}
+
+// TryIndex returns the value of key k in m. If m doesn't contain k, it
+// short-circuits the execution of the current function with msg and the key as
+// an error. PassTo must be installed with defer before.
+func TryIndex[K comparable, V any](m map[K]V, k K, msg string) V {
+	v, ok := m[k]
+	Assertf(ok, "%s: %v", msg, k)
+	return v
+}
+
+// TryNotNil is like Try for functions that return a pointer and an error, but
+// additionally short-circuits the execution of the current function with msg
+// as an error if the pointer is nil. PassTo must be installed with defer
+// before.
+func TryNotNil[A any](a *A, err error, msg string) *A {
+	Check(err)
+	Assert(a != nil, msg)
+	return a
+}
+
+// TryOk is like Try for functions that return a value and a bool instead of an
+// error. It short-circuits the execution of the current function with msg as
+// an error if ok is false. Otherwise it only returns the value. PassTo must be
+// installed with defer before.
+func TryOk[A any](a A, ok bool, msg string) A {
+	Assert(ok, msg)
+	return a
+}
+
+// TryOk2 is TryOk for functions with 2-ary results.
+func TryOk2[A, B any](a A, b B, ok bool, msg string) (A, B) {
+	Assert(ok, msg)
+	return a, b
+}
+
+// TryOk3 is TryOk for functions with 3-ary results.
+func TryOk3[A, B, C any](a A, b B, c C, ok bool, msg string) (A, B, C) {
+	Assert(ok, msg)
+	return a, b, c
+}
+
+// TryEach calls f for each of items in order. It short-circuits the execution
+// of the current function with the first error returned by f, wrapped with the
+// index of the item. PassTo must be installed with defer before.
+func TryEach[T any](items []T, f func(T) error) {
+	for i, item := range items {
+		Checkf(f(item), "item %d", i)
+	}
+}
+
+// TryMapSlice returns the results of f called for each of items. Like
+// TryEach, it short-circuits the execution of the current function with the
+// first error returned by f, wrapped with the index of the item. PassTo must be
+// installed with defer before.
+func TryMapSlice[T, R any](items []T, f func(T) (R, error)) []R {
+	results := make([]R, len(items))
+	for i, item := range items {
+		r, err := f(item)
+		Checkf(err, "item %d", i)
+		results[i] = r
+	}
+	return results
+}
+
+// TryReduce folds items into an accumulator starting with init by calling f
+// for each of them in order. Like TryEach, it short-circuits the execution of
+// the current function with the first error returned by f, wrapped with the
+// index of the item. PassTo must be installed with defer before.
+func TryReduce[T, Acc any](items []T, init Acc, f func(Acc, T) (Acc, error)) Acc {
+	acc := init
+	for i, item := range items {
+		next, err := f(acc, item)
+		Checkf(err, "item %d", i)
+		acc = next
+	}
+	return acc
+}
+
+// ErrTimeout is the error of a short-circuit by TryTimeout.
+var ErrTimeout = errors.New("timeout")
+
+// TryTimeout calls fn in a new goroutine and returns its result value like Try.
+// If fn doesn't return within d, it short-circuits the execution of the
+// current function with ErrTimeout. Short-circuits and other panics in fn are
+// recovered like with RecoverAll. Note that fn can't be interrupted, so after a
+// timeout the goroutine keeps running until fn returns. PassTo must be
+// installed with defer before.
+func TryTimeout[A any](d time.Duration, fn func() (A, error)) A {
+	ch := make(chan *Result[A], 1)
+	go func() {
+		r := &Result[A]{}
+		defer func() { ch <- r }()
+		defer func() {
+			// Short-circuits of fn are kept as they are, so that they are
+			// re-raised without being processed by shortCircuit again.
+			if v := recover(); v != nil {
+				if e, ok := v.(shortCircuitError); ok {
+					r.err = e
+				} else {
+					r.err = panicError(v)
+				}
+			}
+		}()
+		r.a, r.err = fn()
+	}()
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case r := <-ch:
+		if e, ok := r.err.(shortCircuitError); ok {
+			panic(e)
+		}
+		return r.Try()
+	case <-timer.C:
+		shortCircuit(fmt.Errorf("%w after %v", ErrTimeout, d))
+		panic("unreachable")
+	}
+}
+
+// TryRetry calls fn up to attempts times, waiting backoff between the calls,
+// and returns the result value of the first call that doesn't fail. If all calls
+// fail, it short-circuits the execution of the current function with the last
+// error, wrapped with the number of attempts. fn is called at least once.
+// PassTo must be installed with defer before.
+func TryRetry[A any](attempts int, backoff time.Duration, fn func() (A, error)) A {
+	for i := 1; ; i++ {
+		a, err := fn()
+		if err == nil {
+			return a
+		}
+		if i >= attempts {
+			Checkf(err, "after %d attempts", i)
+		}
+		time.Sleep(backoff)
+	}
+}
+
+// TryExit is like Try for functions running a subprocess, like the Output
+// method of exec.Cmd. If the error provides an exit code, it is wrapped with it
+// and, for an *exec.ExitError, with the captured stderr. PassTo must be
+// installed with defer before.
+func TryExit(out []byte, err error) []byte {
+	var coder interface{ ExitCode() int }
+	if errors.As(err, &coder) {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			stderr := strings.TrimSpace(string(exitErr.Stderr))
+			Checkf(err, "exit code %d, stderr %q", coder.ExitCode(), stderr)
+		}
+		Checkf(err, "exit code %d", coder.ExitCode())
+	}
+	Check(err)
+	return out
+}
+
+// TryScan is like Check for the error of a Scan method, e.g. of sql.Rows, and
+// wraps it with "scan". PassTo must be installed with defer before.
+func TryScan(err error) {
+	Check(err, "scan")
+}
+
+// ScanInto calls the Scan method of rows with dest and short-circuits like
+// TryScan if it fails. PassTo must be installed with defer before.
+func ScanInto(rows interface{ Scan(dest ...any) error }, dest ...any) {
+	TryScan(rows.Scan(dest...))
+}
+
+// ReadFile is like os.ReadFile, but short-circuits the execution of the current
+// function with the error wrapped with "reading file". PassTo must be installed
+// with defer before.
+func ReadFile(path string) []byte {
+	return Do(os.ReadFile(path)).Or("reading file")
+}
+
+// OpenFile is like os.Open, but short-circuits the execution of the current
+// function with the error wrapped with "opening file". PassTo must be installed
+// with defer before.
+func OpenFile(path string) *os.File {
+	return Do(os.Open(path)).Or("opening file")
+}
+
+// ReadAll is like io.ReadAll, but short-circuits the execution of the current
+// function with the error wrapped with "reading data". PassTo must be installed
+// with defer before.
+func ReadAll(r io.Reader) []byte {
+	return Do(io.ReadAll(r)).Or("reading data")
+}
+
+// TryAtoi is like strconv.Atoi, but short-circuits the execution of the
+// current function with the error wrapped with s. PassTo must be installed
+// with defer before.
+func TryAtoi(s string) int {
+	return Do(strconv.Atoi(s)).Orf("invalid integer %q", s)
+}
+
+// TryParseFloat is like strconv.ParseFloat, but short-circuits the execution of
+// the current function with the error wrapped with s. PassTo must be installed
+// with defer before.
+func TryParseFloat(s string, bitSize int) float64 {
+	return Do(strconv.ParseFloat(s, bitSize)).Orf("invalid float %q", s)
+}
+
+// TryParseBool is like strconv.ParseBool, but short-circuits the execution of
+// the current function with the error wrapped with s. PassTo must be installed
+// with defer before.
+func TryParseBool(s string) bool {
+	return Do(strconv.ParseBool(s)).Orf("invalid bool %q", s)
+}
+
+// Must is like Try, but panics with err instead of short-circuiting. Since
+// the panic is not a short-circuit, it is not intercepted by PassTo. Must should
+// therefore only be used where an error indicates a programming error.
+func Must[A any](a A, err error) A {
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+// Try2 is Try for functions with 2-ary results.
+func Try2[A, B any](a A, b B, err error) (A, B) {
+	Check(err)
+	return a, b
+}
+
+// Try3 is Try for functions with 3-ary results.
+func Try3[A, B, C any](a A, b B, c C, err error) (A, B, C) {
+	Check(err)
+	return a, b, c
+}
+
+// Try4 is Try for functions with 4-ary results.
+func Try4[A, B, C, D any](a A, b B, c C, d D, err error) (A, B, C, D) {
+	Check(err)
+	return a, b, c, d
+}
+
+// Try5 is Try for functions with 5-ary results.
+func Try5[A, B, C, D, E any](a A, b B, c C, d D, e E, err error) (A, B, C, D, E) {
+	Check(err)
+	return a, b, c, d, e
+}
+
+// Try6 is Try for functions with 6-ary results.
+func Try6[A, B, C, D, E, F any](a A, b B, c C, d D, e E, f F, err error) (A, B, C, D, E, F) {
+	Check(err)
+	return a, b, c, d, e, f
+}
+
+// Try7 is Try for functions with 7-ary results.
+func Try7[A, B, C, D, E, F, G any](a A, b B, c C, d D, e E, f F, g G, err error) (A, B, C, D, E, F, G) {
+	Check(err)
+	return a, b, c, d, e, f, g
+}
+
+// Try8 is Try for functions with 8-ary results.
+func Try8[A, B, C, D, E, F, G, H any](a A, b B, c C, d D, e E, f F, g G, h H, err error) (A, B, C, D, E, F, G, H) {
+	Check(err)
+	return a, b, c, d, e, f, g, h
+}
+
+// Try9 is Try for functions with 9-ary results.
+func Try9[A, B, C, D, E, F, G, H, I any](a A, b B, c C, d D, e E, f F, g G, h H, i I, err error) (A, B, C, D, E, F, G, H, I) {
+	Check(err)
+	return a, b, c, d, e, f, g, h, i
+}
+
+// Try10 is Try for functions with 10-ary results.
+func Try10[A, B, C, D, E, F, G, H, I, J any](a A, b B, c C, d D, e E, f F, g G, h H, i I, j J, err error) (A, B, C, D, E, F, G, H, I, J) {
+	Check(err)
+	return a, b, c, d, e, f, g, h, i, j
+}
+
+type Result[A any] struct {
+	a   A
+	err error
+}
+
+type Result2[A, B any] struct {
+	a   A
+	b   B
+	err error
+}
+
+type Result3[A, B, C any] struct {
+	a   A
+	b   B
+	c   C
+	err error
+}
+
+type Result4[A, B, C, D any] struct {
+	a   A
+	b   B
+	c   C
+	d   D
+	err error
+}
+
+type Result5[A, B, C, D, E any] struct {
+	a   A
+	b   B
+	c   C
+	d   D
+	e   E
+	err error
+}
+
+type ResultErr struct {
+	err error
+}
+
+// Do is an alternative to Try that allows to wrap the short-circuit error with
+// a description by appending the Or() method.
+func Do[A any](a A, err error) *Result[A] {
+	return &Result[A]{a, err}
+}
+
+// Do2 is Do for 2-ary results.
+func Do2[A, B any](a A, b B, err error) *Result2[A, B] {
+	return &Result2[A, B]{a, b, err}
+}
+
+// Do3 is Do for 3-ary results.
+func Do3[A, B, C any](a A, b B, c C, err error) *Result3[A, B, C] {
+	return &Result3[A, B, C]{a, b, c, err}
+}
+
+// Do4 is Do for 4-ary results.
+func Do4[A, B, C, D any](a A, b B, c C, d D, err error) *Result4[A, B, C, D] {
+	return &Result4[A, B, C, D]{a, b, c, d, err}
+}
+
+// Do5 is Do for 5-ary results.
+func Do5[A, B, C, D, E any](a A, b B, c C, d D, e E, err error) *Result5[A, B, C, D, E] {
+	return &Result5[A, B, C, D, E]{a, b, c, d, e, err}
+}
+
+// DoFunc is like Do, but calls fn to obtain the result:
+//
+//	v := se.DoFunc(func() (int, error) { ... }).Or("compute")
+func DoFunc[A any](fn func() (A, error)) *Result[A] {
+	return Do(fn())
+}
+
+// DoErr is an alternative to Check for functions that only return an error,
+// that allows to wrap the short-circuit error with a description by appending
+// the Or() method.
+func DoErr(err error) *ResultErr {
+	return &ResultErr{err}
+}
+
+// Or short-circuits the execution of the current function with the error
+// wrapped with msg, if it is not nil. PassTo must be installed with defer
+// before.
+func (r *ResultErr) Or(msg string) {
+	Check(r.err, msg)
+}
+
+// Or returns only the result value of the function called by Do if its returned
+// error is nil. Otherwise it wraps the error with msg and short-circuits the
+// execution of the current function. To short-circuit with the original error
+// instead, use Try. PassTo must be installed with defer before.
+func (r *Result[A]) Or(msg string) A {
+	Check(r.err, msg)
+	return r.a
+}
+
+// Or for 2-ary results.
+func (r *Result2[A, B]) Or(msg string) (A, B) {
+	Check(r.err, msg)
+	return r.a, r.b
+}
+
+// Or for 3-ary results.
+func (r *Result3[A, B, C]) Or(msg string) (A, B, C) {
+	Check(r.err, msg)
+	return r.a, r.b, r.c
+}
+
+// Or for 4-ary results.
+func (r *Result4[A, B, C, D]) Or(msg string) (A, B, C, D) {
+	Check(r.err, msg)
+	return r.a, r.b, r.c, r.d
+}
+
+// Or for 5-ary results.
 func (r *Result5[A, B, C, D, E]) Or(msg string) (A, B, C, D, E) {
 	Check(r.err, msg)
 	return r.a, r.b, r.c, r.d, r.e
 }
+
+// Try is like Or, but doesn't wrap the error. PassTo must be installed with
+// defer before.
+func (r *Result[A]) Try() A {
+	Check(r.err)
+	return r.a
+}
+
+// Try for 2-ary results.
+func (r *Result2[A, B]) Try() (A, B) {
+	Check(r.err)
+	return r.a, r.b
+}
+
+// Try for 3-ary results.
+func (r *Result3[A, B, C]) Try() (A, B, C) {
+	Check(r.err)
+	return r.a, r.b, r.c
+}
+
+// Try for 4-ary results.
+func (r *Result4[A, B, C, D]) Try() (A, B, C, D) {
+	Check(r.err)
+	return r.a, r.b, r.c, r.d
+}
+
+// Try for 5-ary results.
+func (r *Result5[A, B, C, D, E]) Try() (A, B, C, D, E) {
+	Check(r.err)
+	return r.a, r.b, r.c, r.d, r.e
+}
+
+// Orf is like Or, but wraps the error with a message formatted according to
+// format and args. PassTo must be installed with defer before.
+func (r *Result[A]) Orf(format string, args ...any) A {
+	Checkf(r.err, format, args...)
+	return r.a
+}
+
+// Orf for 2-ary results.
+func (r *Result2[A, B]) Orf(format string, args ...any) (A, B) {
+	Checkf(r.err, format, args...)
+	return r.a, r.b
+}
+
+// Orf for 3-ary results.
+func (r *Result3[A, B, C]) Orf(format string, args ...any) (A, B, C) {
+	Checkf(r.err, format, args...)
+	return r.a, r.b, r.c
+}
+
+// Orf for 4-ary results.
+func (r *Result4[A, B, C, D]) Orf(format string, args ...any) (A, B, C, D) {
+	Checkf(r.err, format, args...)
+	return r.a, r.b, r.c, r.d
+}
+
+// Orf for 5-ary results.
+func (r *Result5[A, B, C, D, E]) Orf(format string, args ...any) (A, B, C, D, E) {
+	Checkf(r.err, format, args...)
+	return r.a, r.b, r.c, r.d, r.e
+}
+
+// OrDefault returns the result value of the function called by Do if its
+// returned error is nil. Otherwise it returns def. It never short-circuits, so
+// PassTo is not required.
+func (r *Result[A]) OrDefault(def A) A {
+	if r.err != nil {
+		return def
+	}
+	return r.a
+}
+
+// OrDefault for 2-ary results.
+func (r *Result2[A, B]) OrDefault(defA A, defB B) (A, B) {
+	if r.err != nil {
+		return defA, defB
+	}
+	return r.a, r.b
+}
+
+// OrDefault for 3-ary results.
+func (r *Result3[A, B, C]) OrDefault(defA A, defB B, defC C) (A, B, C) {
+	if r.err != nil {
+		return defA, defB, defC
+	}
+	return r.a, r.b, r.c
+}
+
+// OrDefault for 4-ary results.
+func (r *Result4[A, B, C, D]) OrDefault(defA A, defB B, defC C, defD D) (A, B, C, D) {
+	if r.err != nil {
+		return defA, defB, defC, defD
+	}
+	return r.a, r.b, r.c, r.d
+}
+
+// OrDefault for 5-ary results.
+func (r *Result5[A, B, C, D, E]) OrDefault(defA A, defB B, defC C, defD D, defE E) (A, B, C, D, E) {
+	if r.err != nil {
+		return defA, defB, defC, defD, defE
+	}
+	return r.a, r.b, r.c, r.d, r.e
+}
+
+// OrElse returns the result value of the function called by Do if its returned
+// error is nil. Otherwise it returns the result of f called with the error. It
+// never short-circuits, so PassTo is not required.
+func (r *Result[A]) OrElse(f func(error) A) A {
+	if r.err != nil {
+		return f(r.err)
+	}
+	return r.a
+}
+
+// OrZero returns the result value of the function called by Do if its returned
+// error is nil. Otherwise the error is discarded and the zero value is
+// returned. It never short-circuits, so PassTo is not required.
+func (r *Result[A]) OrZero() (a A) {
+	if r.err != nil {
+		return
+	}
+	return r.a
+}
+
+// OrZero for 2-ary results.
+func (r *Result2[A, B]) OrZero() (a A, b B) {
+	if r.err != nil {
+		return
+	}
+	return r.a, r.b
+}
+
+// OrZero for 3-ary results.
+func (r *Result3[A, B, C]) OrZero() (a A, b B, c C) {
+	if r.err != nil {
+		return
+	}
+	return r.a, r.b, r.c
+}
+
+// OrZero for 4-ary results.
+func (r *Result4[A, B, C, D]) OrZero() (a A, b B, c C, d D) {
+	if r.err != nil {
+		return
+	}
+	return r.a, r.b, r.c, r.d
+}
+
+// OrZero for 5-ary results.
+func (r *Result5[A, B, C, D, E]) OrZero() (a A, b B, c C, d D, e E) {
+	if r.err != nil {
+		return
+	}
+	return r.a, r.b, r.c, r.d, r.e
+}
+
+// OrLog is like OrZero, but logs the error wrapped with msg to logger before it
+// is discarded. If logger is nil, the standard logger of the log package is
+// used.
+func (r *Result[A]) OrLog(logger *log.Logger, msg string) (a A) {
+	if r.err != nil {
+		if logger == nil {
+			logger = log.Default()
+		}
+		logger.Print(wrap(r.err, msg))
+		return
+	}
+	return r.a
+}
+
+// OrContinue returns the result value of the function called by Do and true if
+// its returned error is nil. Otherwise the error is discarded and the zero
+// value and false are returned. It never short-circuits, so PassTo is not
+// required:
+//
+//	if v, ok := se.Do(f()).OrContinue(); ok {
+//		...
+//	}
+func (r *Result[A]) OrContinue() (a A, ok bool) {
+	if r.err != nil {
+		return
+	}
+	return r.a, true
+}
+
+// OrContinue for 2-ary results.
+func (r *Result2[A, B]) OrContinue() (a A, b B, ok bool) {
+	if r.err != nil {
+		return
+	}
+	return r.a, r.b, true
+}
+
+// OrContinue for 3-ary results.
+func (r *Result3[A, B, C]) OrContinue() (a A, b B, c C, ok bool) {
+	if r.err != nil {
+		return
+	}
+	return r.a, r.b, r.c, true
+}
+
+// OrContinue for 4-ary results.
+func (r *Result4[A, B, C, D]) OrContinue() (a A, b B, c C, d D, ok bool) {
+	if r.err != nil {
+		return
+	}
+	return r.a, r.b, r.c, r.d, true
+}
+
+// OrContinue for 5-ary results.
+func (r *Result5[A, B, C, D, E]) OrContinue() (a A, b B, c C, d D, e E, ok bool) {
+	if r.err != nil {
+		return
+	}
+	return r.a, r.b, r.c, r.d, r.e, true
+}
+
+// OrReturn is a non-panicking alternative to Or. If the error returned by the
+// function called by Do is nil, it returns the result value and true.
+// Otherwise it stores the error wrapped with msg in the variable err is
+// pointing to and returns the zero value and false:
+//
+//	v, ok := se.Do(f()).OrReturn(&err, "f failed")
+//	if !ok {
+//		return
+//	}
+func (r *Result[A]) OrReturn(err *error, msg string) (a A, ok bool) {
+	if r.err != nil {
+		*err = wrapMsg(r.err, []string{msg})
+		return
+	}
+	return r.a, true
+}
+
+// Err returns the error returned by the function called by Do without
+// short-circuiting.
+func (r *Result[A]) Err() error {
+	return r.err
+}
+
+// Err for 2-ary results.
+func (r *Result2[A, B]) Err() error {
+	return r.err
+}
+
+// Err for 3-ary results.
+func (r *Result3[A, B, C]) Err() error {
+	return r.err
+}
+
+// Err for 4-ary results.
+func (r *Result4[A, B, C, D]) Err() error {
+	return r.err
+}
+
+// Err for 5-ary results.
+func (r *Result5[A, B, C, D, E]) Err() error {
+	return r.err
+}
+
+// Unwrap returns the result value and the error returned by the function
+// called by Do without short-circuiting.
+func (r *Result[A]) Unwrap() (A, error) {
+	return r.a, r.err
+}
+
+// Unwrap for 2-ary results.
+func (r *Result2[A, B]) Unwrap() (A, B, error) {
+	return r.a, r.b, r.err
+}
+
+// Unwrap for 3-ary results.
+func (r *Result3[A, B, C]) Unwrap() (A, B, C, error) {
+	return r.a, r.b, r.c, r.err
+}
+
+// Unwrap for 4-ary results.
+func (r *Result4[A, B, C, D]) Unwrap() (A, B, C, D, error) {
+	return r.a, r.b, r.c, r.d, r.err
+}
+
+// Unwrap for 5-ary results.
+func (r *Result5[A, B, C, D, E]) Unwrap() (A, B, C, D, E, error) {
+	return r.a, r.b, r.c, r.d, r.e, r.err
+}
+
+// OrPanic is like Or, but panics with the wrapped error instead of
+// short-circuiting. Since the panic is not a short-circuit, it is not
+// intercepted by PassTo. Like Must, it should only be used where an error
+// indicates a programming error, or at the top level, e.g. in main.
+func (r *Result[A]) OrPanic(msg string) A {
+	if r.err != nil {
+		panic(wrap(r.err, msg))
+	}
+	return r.a
+}
+
+// OrPanic for 2-ary results.
+func (r *Result2[A, B]) OrPanic(msg string) (A, B) {
+	if r.err != nil {
+		panic(wrap(r.err, msg))
+	}
+	return r.a, r.b
+}
+
+// OrPanic for 3-ary results.
+func (r *Result3[A, B, C]) OrPanic(msg string) (A, B, C) {
+	if r.err != nil {
+		panic(wrap(r.err, msg))
+	}
+	return r.a, r.b, r.c
+}
+
+// OrPanic for 4-ary results.
+func (r *Result4[A, B, C, D]) OrPanic(msg string) (A, B, C, D) {
+	if r.err != nil {
+		panic(wrap(r.err, msg))
+	}
+	return r.a, r.b, r.c, r.d
+}
+
+// OrPanic for 5-ary results.
+func (r *Result5[A, B, C, D, E]) OrPanic(msg string) (A, B, C, D, E) {
+	if r.err != nil {
+		panic(wrap(r.err, msg))
+	}
+	return r.a, r.b, r.c, r.d, r.e
+}
+
+// Must is like OrPanic, but panics with the unwrapped error.
+func (r *Result[A]) Must() A {
+	if r.err != nil {
+		panic(r.err)
+	}
+	return r.a
+}
+
+// Must for 2-ary results.
+func (r *Result2[A, B]) Must() (A, B) {
+	if r.err != nil {
+		panic(r.err)
+	}
+	return r.a, r.b
+}
+
+// Must for 3-ary results.
+func (r *Result3[A, B, C]) Must() (A, B, C) {
+	if r.err != nil {
+		panic(r.err)
+	}
+	return r.a, r.b, r.c
+}
+
+// Must for 4-ary results.
+func (r *Result4[A, B, C, D]) Must() (A, B, C, D) {
+	if r.err != nil {
+		panic(r.err)
+	}
+	return r.a, r.b, r.c, r.d
+}
+
+// Must for 5-ary results.
+func (r *Result5[A, B, C, D, E]) Must() (A, B, C, D, E) {
+	if r.err != nil {
+		panic(r.err)
+	}
+	return r.a, r.b, r.c, r.d, r.e
+}
+
+// Tap calls f with the result value of the function called by Do if its
+// returned error is nil, and returns r for chaining:
+//
+//	v := se.Do(fetch()).Tap(logHit).Or("fetch failed")
+func (r *Result[A]) Tap(f func(A)) *Result[A] {
+	if r.err == nil {
+		f(r.a)
+	}
+	return r
+}
+
+// TapErr calls f with the error returned by the function called by Do if it is
+// not nil, and returns r for chaining:
+//
+//	v := se.Do(fetch()).TapErr(logMiss).Or("fetch failed")
+func (r *Result[A]) TapErr(f func(error)) *Result[A] {
+	if r.err != nil {
+		f(r.err)
+	}
+	return r
+}
+
+// Context returns a Result with the error returned by the function called by
+// Do wrapped with msg, if it is not nil. This allows to add several layers of
+// context:
+//
+//	se.Do(f()).Context("layer1").Context("layer2").Or("final") // "final: layer2: layer1: <err>"
+func (r *Result[A]) Context(msg string) *Result[A] {
+	if r.err != nil {
+		return &Result[A]{r.a, wrap(r.err, msg)}
+	}
+	return r
+}
+
+// Filter returns a Result with msg as error if the error returned by the
+// function called by Do is nil, but pred called with the result value returns
+// false. Otherwise r is returned unchanged and pred is not called:
+//
+//	age := se.Do(getAge()).Filter(func(a int) bool { return a >= 0 }, "negative age").Or("bad age")
+func (r *Result[A]) Filter(pred func(A) bool, msg string) *Result[A] {
+	if r.err == nil && !pred(r.a) {
+		return &Result[A]{r.a, errors.New(msg)}
+	}
+	return r
+}
+
+// Map returns a Result with the result value of r transformed by f. If the
+// error of r is not nil, f is not called and the error is passed on. Since Go
+// doesn't allow type parameters on methods, Map is a function:
+//
+//	cfg := se.Map(se.Do(readConfig()), parse).Or("bad config")
+func Map[A, R any](r *Result[A], f func(A) R) *Result[R] {
+	if r.err != nil {
+		return &Result[R]{err: r.err}
+	}
+	return &Result[R]{f(r.a), nil}
+}
+
+// AndThen returns a Result with the results of f called with the result value
+// of r. If the error of r is not nil, f is not called and the error is passed
+// on. This allows to chain fallible functions and handle their errors at once:
+//
+//	v := se.AndThen(se.AndThen(se.Do(step1()), step2), step3).Or("pipeline failed")
+func AndThen[A, R any](r *Result[A], f func(A) (R, error)) *Result[R] {
+	if r.err != nil {
+		return &Result[R]{err: r.err}
+	}
+	return Do(f(r.a))
+}
+
+// ErrNilResult is the error of a Result flattened by Flatten whose inner Result
+// is nil.
+var ErrNilResult = errors.New("shorterr: nil Result")
+
+// Flatten collapses a Result holding a Result into a single Result with the
+// first error that is not nil, starting with the outer one. A nil inner Result
+// is treated as ErrNilResult.
+func Flatten[A any](r *Result[*Result[A]]) *Result[A] {
+	if r.err != nil {
+		return &Result[A]{err: r.err}
+	}
+	if r.a == nil {
+		return &Result[A]{err: ErrNilResult}
+	}
+	return r.a
+}
+
+// Zip combines ra and rb into a Result2 with the first error that is not nil:
+//
+//	a, b := se.Zip(se.Do(fetchA()), se.Do(fetchB())).Or("combine failed")
+func Zip[A, B any](ra *Result[A], rb *Result[B]) *Result2[A, B] {
+	err := ra.err
+	if err == nil {
+		err = rb.err
+	}
+	return &Result2[A, B]{ra.a, rb.a, err}
+}
+
+// Collector accumulates errors without short-circuiting, so that all of them
+// can be reported at once. The zero value is ready to use:
+//
+//	var c se.Collector
+//	c.Assert(len(name) > 0, "missing name")
+//	c.Add(validateAge(age))
+//	se.Check(c.Result())
+type Collector struct {
+	errs []error
+}
+
+// Add records err if it is not nil.
+func (c *Collector) Add(err error) {
+	if err != nil {
+		c.errs = append(c.errs, err)
+	}
+}
+
+// Assert records msg as an error if ok is false.
+func (c *Collector) Assert(ok bool, msg string) {
+	if !ok {
+		c.errs = append(c.errs, errors.New(msg))
+	}
+}
+
+// Result returns all recorded errors joined by errors.Join, or nil if there are
+// none.
+func (c *Collector) Result() error {
+	return errors.Join(c.errs...)
+}
+
+// Cleanups is a list of cleanup functions that are run in reverse order of
+// their registration. The zero value is ready to use. Together with TryClose
+// it combines opening and closing of resources:
+//
+//	func Foo() (err error) {
+//		var c se.Cleanups
+//		defer c.Run()
+//		defer se.PassTo(&err)
+//		f := se.TryClose(os.Open("data.json")).DeferTo(&c)
+//	...
+type Cleanups struct {
+	fns []func()
+}
+
+// Defer registers f to be called by Run.
+func (c *Cleanups) Defer(f func()) {
+	c.fns = append(c.fns, f)
+}
+
+// Run calls all registered functions in reverse order and clears the list.
+func (c *Cleanups) Run() {
+	for i := len(c.fns) - 1; i >= 0; i-- {
+		c.fns[i]()
+	}
+	c.fns = nil
+}
+
+// Closing holds a resource returned by TryClose.
+type Closing[C io.Closer] struct {
+	c C
+}
+
+// TryClose is like Try for functions returning a resource that needs to be
+// closed. The resource is obtained by appending the DeferTo() method. PassTo
+// must be installed with defer before.
+func TryClose[C io.Closer](c C, err error) *Closing[C] {
+	Check(err)
+	return &Closing[C]{c}
+}
+
+// DeferTo registers the resource to be closed by c and returns it. The error
+// returned by its Close method is discarded.
+func (r *Closing[C]) DeferTo(c *Cleanups) C {
+	c.Defer(func() { r.c.Close() })
+	return r.c
+}
+
+// Recorder records errors wrapped like by Check instead of short-circuiting.
+// The zero value is ready to use.
+type Recorder struct {
+	errs []error
+}
+
+// Check records err wrapped with the optional msg like the Check function, if
+// it is not nil.
+func (r *Recorder) Check(err error, msg ...string) {
+	if err != nil {
+		r.errs = append(r.errs, wrapMsg(err, msg))
+	}
+}
+
+// Errors returns all recorded errors.
+func (r *Recorder) Errors() []error {
+	return r.errs
+}
+
+// Scope combines PassTo and Cleanups. It must be created at the beginning of the
+// current function and its Done method installed with defer before the other
+// short-circuit functions are used:
+//
+//	func Foo() (err error) {
+//		s := se.NewScope(&err)
+//		defer s.Done()
+//		f := se.TryClose(os.Open("data.json")).DeferTo(&s.Cleanups)
+//		s.Defer(func() { ... })
+//	...
+type Scope struct {
+	Cleanups
+	err *error
+}
+
+// NewScope returns a Scope that stores an intercepted error in the variable err
+// is pointing to.
+func NewScope(err *error) *Scope {
+	return &Scope{err: err}
+}
+
+// Done is like PassTo and additionally runs all registered cleanup functions in
+// reverse order, regardless of whether the function short-circuited.
+func (s *Scope) Done() {
+	defer s.Run()
+	if e := intercept(recover()); e != nil {
+		*s.err = e
+	}
+}
+
+// WithRecover returns a function that calls fn with PassTo installed, so that a
+// short-circuit in fn is returned as its error. This allows to pass functions
+// using the short-circuit functions to code expecting a plain func() error.
+func WithRecover(fn func() error) func() error {
+	return func() (err error) {
+		defer PassTo(&err)
+		return fn()
+	}
+}
+
+// TryBool calls fn and reports whether it returned without short-circuiting.
+// Other panics are passed on unchanged.
+func TryBool(fn func()) bool {
+	return catch(fn) == nil
+}
+
+// catch calls fn and returns the error it short-circuited with, if any.
+func catch(fn func()) (err error) {
+	defer PassTo(&err)
+	fn()
+	return
+}
+
+// TestingT is the subset of testing.TB used by the test helpers.
+type TestingT interface {
+	Helper()
+	Fatalf(format string, args ...any)
+}
+
+// MustNotShortCircuit calls fn and fails the test t if fn short-circuits.
+// Other panics are passed on unchanged.
+func MustNotShortCircuit(t TestingT, fn func()) {
+	t.Helper()
+	if err := catch(fn); err != nil {
+		t.Fatalf("unexpected short-circuit: %v", err)
+	}
+}
+
+// RequireNoError is like Check, but fails the test t with the wrapped error
+// instead of short-circuiting.
+func RequireNoError(t TestingT, err error, msg ...string) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("%v", wrapMsg(err, msg))
+	}
+}
+
+// RequireTrue is like Assert, but fails the test t with msg instead of
+// short-circuiting.
+func RequireTrue(t TestingT, ok bool, msg string) {
+	t.Helper()
+	if !ok {
+		t.Fatalf("%s", msg)
+	}
+}
+
+// Go runs fn in a new goroutine and sends its returned error on the returned
+// channel, which is closed afterwards. Short-circuits and other panics in fn
+// are recovered like with RecoverAll and sent as the error, so fn can use the
+// short-circuit functions without installing PassTo itself:
+//
+//	errc := se.Go(func() error {
+//		data := se.Try(fetch())
+//	...
+func Go(fn func() error) <-chan error {
+	ch := make(chan error, 1)
+	go func() {
+		defer close(ch)
+		ch <- run(fn)
+	}()
+	return ch
+}
+
+func run(fn func() error) (err error) {
+	defer RecoverAll(&err)
+	return fn()
+}
+
+// Group runs functions in goroutines like Go and collects the first returned
+// error, similar to golang.org/x/sync/errgroup. The zero value is ready to use.
+type Group struct {
+	wg   sync.WaitGroup
+	once sync.Once
+	err  error
+}
+
+// Go runs fn in a new goroutine. Short-circuits and other panics in fn are
+// recovered like with RecoverAll.
+func (g *Group) Go(fn func() error) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		if err := run(fn); err != nil {
+			g.once.Do(func() { g.err = err })
+		}
+	}()
+}
+
+// Wait blocks until all functions started with Go have returned and returns the
+// first error, if any.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	return g.err
+}
+
+// CodeError is an error with a machine-readable code. It is marshalled to JSON
+// as {"code":"...","message":"..."}, without the wrapped error.
+type CodeError struct {
+	Code    string
+	Message string
+	err     error
+}
+
+// CheckCode is like Check, but wraps the error in a CodeError with code and
+// msg. PassTo must be installed with defer before.
+func CheckCode(err error, code string, msg string) {
+	if err != nil {
+		shortCircuit(&CodeError{code, msg, err})
+	}
+}
+
+func (e *CodeError) Error() string {
+	if e.Message == "" {
+		return e.err.Error()
+	}
+	return wrap(e.err, e.Message).Error()
+}
+
+func (e *CodeError) Unwrap() error {
+	return e.err
+}
+
+// MarshalJSON implements json.Marshaler.
+func (e *CodeError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}{e.Code, e.Message})
+}
+
+// KVError is an error with key/value pairs for structured logging. It
+// implements slog.LogValuer.
+type KVError struct {
+	msg string
+	kv  []any
+	err error
+}
+
+// CheckKV is like Check, but wraps the error in a KVError with msg and the
+// key/value pairs kv, which must have an even length. PassTo must be installed
+// with defer before.
+func CheckKV(err error, msg string, kv ...any) {
+	if len(kv)%2 != 0 {
+		panic(errors.New("shorterr: CheckKV called with odd number of key/value arguments"))
+	}
+	if err != nil {
+		shortCircuit(&KVError{msg, kv, err})
+	}
+}
+
+func (e *KVError) Error() string {
+	if e.msg == "" {
+		return e.err.Error()
+	}
+	return wrap(e.err, e.msg).Error()
+}
+
+func (e *KVError) Unwrap() error {
+	return e.err
+}
+
+// Fields returns the key/value pairs of the error.
+func (e *KVError) Fields() []any {
+	return e.kv
+}
+
+// LogValue implements slog.LogValuer. It returns a group of the error message
+// as "error" and the key/value pairs.
+func (e *KVError) LogValue() slog.Value {
+	attrs := []slog.Attr{slog.String("error", e.Error())}
+	for i := 0; i < len(e.kv); i += 2 {
+		attrs = append(attrs, slog.Any(fmt.Sprint(e.kv[i]), e.kv[i+1]))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// FieldError is a validation error of a field.
+type FieldError struct {
+	field string
+	msg   string
+}
+
+// AssertField is like Assert, but returns a FieldError for field with msg.
+// PassTo must be installed with defer before.
+func AssertField(ok bool, field, msg string) {
+	if !ok {
+		shortCircuit(&FieldError{field, msg})
+	}
+}
+
+func (e *FieldError) Error() string {
+	return e.field + ": " + e.msg
+}
+
+// Field returns the name of the field.
+func (e *FieldError) Field() string {
+	return e.field
+}
+
+// Message returns the message without the field name.
+func (e *FieldError) Message() string {
+	return e.msg
+}
+
+// ErrorContext collects key/value pairs that are attached to an intercepted
+// error. It must be created at the beginning of the current function and its
+// Done method installed with defer before the other short-circuit functions
+// are used:
+//
+//	func Foo() (err error) {
+//		c := se.Capture(&err)
+//		defer c.Done()
+//		c.Set("op", "load")
+//	...
+type ErrorContext struct {
+	err *error
+	kv  []any
+}
+
+// Capture returns an ErrorContext that stores an intercepted error in the
+// variable err is pointing to.
+func Capture(err *error) *ErrorContext {
+	return &ErrorContext{err: err}
+}
+
+// Set sets the value of key, replacing an earlier value of the same key.
+func (c *ErrorContext) Set(key string, value any) {
+	for i := 0; i < len(c.kv); i += 2 {
+		if c.kv[i] == key {
+			c.kv[i+1] = value
+			return
+		}
+	}
+	c.kv = append(c.kv, key, value)
+}
+
+// Done is like PassTo, but the intercepted error is wrapped in a KVError with
+// the key/value pairs set so far, if there are any.
+func (c *ErrorContext) Done() {
+	if e := intercept(recover()); e != nil {
+		if len(c.kv) > 0 {
+			e = &KVError{"", c.kv, e}
+		}
+		*c.err = e
+	}
+}
+
+// statusInternalServerError is http.StatusInternalServerError, defined here to
+// avoid importing net/http into every binary using this package.
+const statusInternalServerError = 500
+
+// HTTPError is an error with an HTTP status code.
+type HTTPError struct {
+	status int
+	err    error
+}
+
+// CheckHTTP is like Check, but wraps the error in an HTTPError with status.
+// PassTo must be installed with defer before.
+func CheckHTTP(err error, status int) {
+	if err != nil {
+		shortCircuit(&HTTPError{status, err})
+	}
+}
+
+// AssertHTTP is like Assert, but returns msg as an HTTPError with status.
+// PassTo must be installed with defer before.
+func AssertHTTP(ok bool, status int, msg string) {
+	if !ok {
+		shortCircuit(&HTTPError{status, errors.New(msg)})
+	}
+}
+
+func (e *HTTPError) Error() string {
+	return e.err.Error()
+}
+
+func (e *HTTPError) Unwrap() error {
+	return e.err
+}
+
+// StatusCode returns the HTTP status code of the error, or
+// http.StatusInternalServerError if it is not set.
+func (e *HTTPError) StatusCode() int {
+	if e.status == 0 {
+		return statusInternalServerError
+	}
+	return e.status
+}
+
+// StatusCode returns the HTTP status code of the first HTTPError in the chain
+// of err, or http.StatusInternalServerError if there is none.
+func StatusCode(err error) int {
+	var e *HTTPError
+	if errors.As(err, &e) {
+		return e.StatusCode()
+	}
+	return statusInternalServerError
+}
+
+// Chain is a prefix for the errors of a sequence of short-circuit functions
+// belonging to the same operation:
+//
+//	c := se.Chain("loading config")
+//	c.Check(err, "reading") // "loading config: reading: <err>"
+//
+// Since Go doesn't allow type parameters on methods, there are no Try or Do
+// methods. Their errors can be checked with Check instead.
+type Chain string
+
+// Check is like the Check function, but additionally wraps the error with the
+// prefix of c. PassTo must be installed with defer before.
+func (c Chain) Check(err error, msg ...string) {
+	if err != nil {
+		shortCircuit(wrap(wrapMsg(err, msg), string(c)))
+	}
+}
+
+// Checkf is like the Checkf function, but additionally wraps the error with the
+// prefix of c. PassTo must be installed with defer before.
+func (c Chain) Checkf(err error, format string, args ...any) {
+	if err != nil {
+		shortCircuit(wrap(wrapf(err, format, args...), string(c)))
+	}
+}
+
+// Assert is like the Assert function, but additionally wraps the error with the
+// prefix of c. PassTo must be installed with defer before.
+func (c Chain) Assert(ok bool, msg string) {
+	if !ok {
+		shortCircuit(wrap(errors.New(msg), string(c)))
+	}
+}
+
+// Assertf is like the Assertf function, but additionally wraps the error with
+// the prefix of c. PassTo must be installed with defer before.
+func (c Chain) Assertf(ok bool, format string, args ...any) {
+	if !ok {
+		shortCircuit(wrap(fmt.Errorf(format, args...), string(c)))
+	}
+}
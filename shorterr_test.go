@@ -1,11 +1,22 @@
 package shorterr_test
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log"
+	"log/slog"
+	"net/http"
 	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	se "github.com/ansiwen/shorterr"
 )
@@ -70,117 +81,2191 @@ func Example() {
 	// open data.json: no such file or directory
 }
 
+func TestPassTof(t *testing.T) {
+	assert(t, "handle(2): failed", func(x bool) (a []int, err error) {
+		defer se.PassTof(&err, "handle(%d)", 2)
+		a = argsToSlice(se.Try(errFunc1(x)))
+		return
+	})
+	g := func() (err error) {
+		defer se.PassTof(&err, "handle after %w", io.ErrClosedPipe)
+		se.Check(io.EOF)
+		return
+	}
+	if err := g(); !errors.Is(err, io.EOF) || !errors.Is(err, io.ErrClosedPipe) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	f := func() (err error) {
+		defer se.PassTof(&err, "handle")
+		panic("bla")
+	}
+	panicked := false
+	func() {
+		defer func() {
+			s, ok := recover().(string)
+			panicked = ok && s == "bla"
+		}()
+		f()
+	}()
+	if !panicked {
+		t.Fatal("Expected panic")
+	}
+}
+
+func TestPassToJoin(t *testing.T) {
+	assert(t, "failed", func(x bool) (a []int, err error) {
+		defer se.PassToJoin(&err)
+		a = argsToSlice(se.Try(errFunc1(x)))
+		return
+	})
+	f := func() (err error) {
+		defer se.PassToJoin(&err)
+		err = io.ErrClosedPipe
+		se.Check(io.EOF)
+		return
+	}
+	err := f()
+	if !errors.Is(err, io.ErrClosedPipe) || !errors.Is(err, io.EOF) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	g := func() (err error) {
+		defer se.PassToJoin(&err)
+		se.Check(io.EOF)
+		return
+	}
+	if err := g(); err != io.EOF {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestPassToFunc(t *testing.T) {
+	var calls []string
+	done := func(err error, shortCircuited bool) {
+		calls = append(calls, fmt.Sprint(err, shortCircuited))
+	}
+	f := func(e error, fail bool) (err error) {
+		defer se.PassToFunc(&err, done)
+		se.Assert(!fail, "failed")
+		return e
+	}
+	f(nil, false)
+	f(io.EOF, false)
+	f(nil, true)
+	if fmt.Sprint(calls) != "[<nil> false EOF false failed true]" {
+		t.Fatalf("unexpected calls: %q", calls)
+	}
+	calls = nil
+	g := func() (err error) {
+		defer se.PassToFunc(&err, done)
+		panic("bla")
+	}
+	if v := recovered(func() { g() }); v != "bla" || len(calls) != 0 {
+		t.Fatalf("unexpected result: %v, %q", v, calls)
+	}
+}
+
+func TestPassToChan(t *testing.T) {
+	ch := make(chan error, 2)
+	f := func(x bool) {
+		defer se.PassToChan(ch)
+		se.Try(errFunc1(x))
+	}
+	f(true)
+	f(false)
+	close(ch)
+	var errs []error
+	for err := range ch {
+		errs = append(errs, err)
+	}
+	if len(errs) != 1 || errs[0].Error() != "failed" {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	g := func() {
+		defer se.PassToChan(ch)
+		panic("bla")
+	}
+	if v := recovered(g); v != "bla" {
+		t.Fatalf("unexpected panic: %v", v)
+	}
+}
+
+func TestPassToAll(t *testing.T) {
+	var order []int
+	f := func(x bool) (a []int, err error) {
+		order = nil
+		defer se.PassToAll(&err,
+			func() { order = append(order, 1) },
+			func() { order = append(order, 2) })
+		a = argsToSlice(se.Try(errFunc1(x)))
+		return
+	}
+	assert(t, "failed", f)
+	if fmt.Sprint(order) != "[2 1]" {
+		t.Fatalf("unexpected order: %v", order)
+	}
+	f(true)
+	if fmt.Sprint(order) != "[2 1]" {
+		t.Fatalf("unexpected order: %v", order)
+	}
+}
+
+func TestPassToClean(t *testing.T) {
+	assert(t, "failed", func(x bool) (a []int, err error) {
+		defer se.PassToClean(&err, func() { a = nil })
+		a = []int{1}
+		se.Check(errFunc(x))
+		return
+	})
+}
+
+func TestNestedPassTo(t *testing.T) {
+	inner := func(fail bool) (err error) {
+		defer se.PassTo(&err)
+		se.Assert(!fail, "inner")
+		return
+	}
+	outer := func(fail bool) (innerErr, err error) {
+		defer se.PassTo(&err)
+		innerErr = inner(true)
+		se.Assert(inner(false) == nil, "unexpected")
+		se.Assert(!fail, "outer")
+		return
+	}
+	innerErr, err := outer(false)
+	if innerErr == nil || innerErr.Error() != "inner" || err != nil {
+		t.Fatalf("unexpected errors: %v, %v", innerErr, err)
+	}
+	innerErr, err = outer(true)
+	if innerErr == nil || innerErr.Error() != "inner" || err == nil || err.Error() != "outer" {
+		t.Fatalf("unexpected errors: %v, %v", innerErr, err)
+	}
+}
+
+func TestPassToWith(t *testing.T) {
+	assert(t, "wrapped: failed", func(x bool) (a []int, err error) {
+		defer se.PassToWith(&err, func(err error) error {
+			return fmt.Errorf("wrapped: %w", err)
+		})
+		a = argsToSlice(se.Try(errFunc1(x)))
+		return
+	})
+}
+
+func TestWrap(t *testing.T) {
+	assert(t, "wrapped: failed", func(x bool) (a []int, err error) {
+		defer se.Wrap(&err, "wrapped")
+		defer se.PassTo(&err)
+		a = argsToSlice(se.Try(errFunc1(x)))
+		return
+	})
+}
+
+func TestOnError(t *testing.T) {
+	var got []string
+	assert(t, "wrapped: failed", func(x bool) (a []int, err error) {
+		defer se.OnError(&err, func(err error) { got = append(got, err.Error()) })
+		defer se.Wrap(&err, "wrapped")
+		defer se.PassTo(&err)
+		a = argsToSlice(se.Try(errFunc1(x)))
+		return
+	})
+	if len(got) != 1 || got[0] != "wrapped: failed" {
+		t.Fatalf("unexpected calls: %q", got)
+	}
+}
+
 func TestCheck(t *testing.T) {
 	assert(t, "failed", func(x bool) (a []int, err error) {
 		defer se.PassTo(&err)
 		se.Check(errFunc(x))
 		return
-	})
+	})
+}
+
+func TestWrapFormat(t *testing.T) {
+	se.WrapFormat = "[%s] %w"
+	defer func() { se.WrapFormat = "%s: %w" }()
+	assert(t, "[failed2] failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		se.Check(errFunc(x), "failed2")
+		return
+	})
+	assert(t, "[failed 2] failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		a = argsToSlice(se.Do(errFunc1(x)).Orf("failed %d", 2))
+		return
+	})
+}
+
+func TestCheckErrorsAs(t *testing.T) {
+	_, pathErr := os.Open("does-not-exist")
+	f := func() (err error) {
+		defer se.PassTo(&err)
+		se.Check(pathErr, "ctx")
+		return
+	}
+	err := f()
+	var target *os.PathError
+	if !errors.As(err, &target) || target != pathErr {
+		t.Fatalf("Expected *os.PathError, got: %v", err)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("Expected os.ErrNotExist, got: %v", err)
+	}
+}
+
+func TestCheckf(t *testing.T) {
+	assert(t, "failed 2: failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		se.Checkf(errFunc(x), "failed %d", 2)
+		return
+	})
+	g := func() (err error) {
+		defer se.PassTo(&err)
+		se.Checkf(io.EOF, "item %d", 0)
+		return
+	}
+	if err := g(); err == nil || err.Error() != "item 0: EOF" || errors.Unwrap(err) != io.EOF {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	f := func() (err error) {
+		defer se.PassTo(&err)
+		se.Checkf(io.EOF, "closing after %w", io.ErrClosedPipe)
+		return
+	}
+	err := f()
+	if err == nil || err.Error() != "closing after io: read/write on closed pipe: EOF" ||
+		!errors.Is(err, io.EOF) || !errors.Is(err, io.ErrClosedPipe) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestCheckOpaque(t *testing.T) {
+	assert(t, "failed2: failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		se.CheckOpaque(errFunc(x), "failed2")
+		return
+	})
+	f := func() (err error) {
+		defer se.PassTo(&err)
+		se.CheckOpaque(io.EOF, "failed2")
+		return
+	}
+	if err := f(); err == nil || err.Error() != "failed2: EOF" || errors.Is(err, io.EOF) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestCheckWith(t *testing.T) {
+	assert(t, "load > parse: failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		se.CheckWith(errFunc(x), " > ", "load", "parse")
+		return
+	})
+	assert(t, "failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		se.CheckWith(errFunc(x), " > ")
+		return
+	})
+}
+
+func TestCheckLazy(t *testing.T) {
+	called := 0
+	assert(t, "failed2: failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		se.CheckLazy(errFunc(x), func() string {
+			called++
+			return "failed2"
+		})
+		return
+	})
+	if called != 1 {
+		t.Fatalf("expected 1 call, got %d", called)
+	}
+}
+
+func TestCheckAll(t *testing.T) {
+	f := func(errs ...error) (err error) {
+		defer se.PassTo(&err)
+		se.CheckAll(errs...)
+		return
+	}
+	if err := f(nil, nil, nil); err != nil {
+		t.Fatal("Expected no error")
+	}
+	if err := f(nil, io.EOF, io.ErrClosedPipe); err != io.EOF {
+		t.Fatalf("expected: %v got: %v", io.EOF, err)
+	}
+}
+
+func TestCheckJoin(t *testing.T) {
+	f := func(errs ...error) (err error) {
+		defer se.PassTo(&err)
+		se.CheckJoin(errs...)
+		return
+	}
+	if err := f(nil, nil, nil); err != nil {
+		t.Fatal("Expected no error")
+	}
+	err := f(io.EOF, nil, io.ErrClosedPipe)
+	if !errors.Is(err, io.EOF) || !errors.Is(err, io.ErrClosedPipe) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestCheckIf(t *testing.T) {
+	errNotFound := errors.New("not found")
+	f := func(cond bool) (err error) {
+		defer se.PassTo(&err)
+		se.CheckIf(cond, errNotFound)
+		return
+	}
+	if err := f(false); err != nil {
+		t.Fatal("Expected no error")
+	}
+	if err := f(true); err != errNotFound || !errors.Is(err, errNotFound) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	v := recovered(func() { se.CheckIf(true, nil) })
+	if e, ok := v.(error); !ok || e.Error() != "shorterr: CheckIf called with nil sentinel" {
+		t.Fatalf("unexpected panic: %v", v)
+	}
+}
+
+func TestCheckUnless(t *testing.T) {
+	f := func(e error) (err error) {
+		defer se.PassTo(&err)
+		se.CheckUnless(e, io.EOF, io.ErrUnexpectedEOF)
+		return
+	}
+	if err := f(nil); err != nil {
+		t.Fatal("Expected no error")
+	}
+	if err := f(fmt.Errorf("wrapped: %w", io.EOF)); err != nil {
+		t.Fatal("Expected no error")
+	}
+	if err := f(io.ErrClosedPipe); err != io.ErrClosedPipe {
+		t.Fatalf("expected: %v got: %v", io.ErrClosedPipe, err)
+	}
+}
+
+type traceKey struct{}
+
+func TestCheckCtx(t *testing.T) {
+	se.TraceKey = traceKey{}
+	defer func() { se.TraceKey = nil }()
+	ctx := context.WithValue(context.Background(), traceKey{}, "req-1")
+	assert(t, "[req-1] failed2: failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		se.CheckCtx(ctx, errFunc(x), "failed2")
+		return
+	})
+	assert(t, "failed2: failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		se.CheckCtx(context.Background(), errFunc(x), "failed2")
+		return
+	})
+}
+
+func TestCheckContext(t *testing.T) {
+	f := func(ctx context.Context) (err error) {
+		defer se.PassTo(&err)
+		se.CheckContext(ctx)
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := f(ctx); err != nil {
+		t.Fatal("Expected no error")
+	}
+	cancel()
+	if err := f(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected: %v got: %v", context.Canceled, err)
+	}
+}
+
+func TestTry(t *testing.T) {
+	assert(t, "failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		a = argsToSlice(se.Try(errFunc1(x)))
+		return
+	})
+}
+
+func TestTryMsg(t *testing.T) {
+	assert(t, "failed2: failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		v, e := errFunc1(x)
+		a = argsToSlice(se.TryMsg(v, e, "failed2"))
+		return
+	})
+	f := func(useDo bool) (err error) {
+		defer se.PassTo(&err)
+		if useDo {
+			se.Do(0, io.EOF).Or("failed2")
+		}
+		se.TryMsg(0, io.EOF, "failed2")
+		return
+	}
+	if err1, err2 := f(false), f(true); err1.Error() != err2.Error() {
+		t.Fatalf("expected: %v got: %v", err2, err1)
+	}
+}
+
+func TestTryMap(t *testing.T) {
+	assert(t, "failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		a = se.TryMap(func(i int) []int { return []int{i} })(errFunc1(x))
+		return
+	})
+}
+
+func TestTryR(t *testing.T) {
+	assert(t, "failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		a = argsToSlice(se.TryR(errFuncR1(x)))
+		return
+	})
+}
+
+func TestTryR2(t *testing.T) {
+	assert(t, "failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		a = argsToSlice(se.TryR2(errFuncR2(x)))
+		return
+	})
+}
+
+func TestTryR3(t *testing.T) {
+	assert(t, "failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		a = argsToSlice(se.TryR3(errFuncR3(x)))
+		return
+	})
+}
+
+func TestTryCast(t *testing.T) {
+	f := func(v any) (s string, err error) {
+		defer se.PassTo(&err)
+		s = se.TryCast[string](v, "not a string")
+		return
+	}
+	if s, err := f("x"); s != "x" || err != nil {
+		t.Fatalf("unexpected result: %q, %v", s, err)
+	}
+	if s, err := f(1); s != "" || err == nil || err.Error() != "not a string: got int" {
+		t.Fatalf("unexpected result: %q, %v", s, err)
+	}
+	if s, err := f(nil); s != "" || err == nil || err.Error() != "not a string: got <nil>" {
+		t.Fatalf("unexpected result: %q, %v", s, err)
+	}
+}
+
+func TestAssertType(t *testing.T) {
+	f := func(v any) (err error) {
+		defer se.PassTo(&err)
+		se.AssertType[io.Reader](v, "not a reader")
+		return
+	}
+	if err := f(strings.NewReader("")); err != nil {
+		t.Fatal("Expected no error")
+	}
+	if err := f("x"); err == nil || err.Error() != "not a reader: got string" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestTryIndex(t *testing.T) {
+	m := map[string]int{"one": 1, "zero": 0}
+	f := func(k string) (v int, err error) {
+		defer se.PassTo(&err)
+		v = se.TryIndex(m, k, "missing key")
+		return
+	}
+	if v, err := f("one"); v != 1 || err != nil {
+		t.Fatalf("unexpected result: %d, %v", v, err)
+	}
+	if v, err := f("zero"); v != 0 || err != nil {
+		t.Fatalf("unexpected result: %d, %v", v, err)
+	}
+	if _, err := f("two"); err == nil || err.Error() != "missing key: two" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestTryNotNil(t *testing.T) {
+	f := func(p *int, e error) (a *int, err error) {
+		defer se.PassTo(&err)
+		a = se.TryNotNil(p, e, "nil result")
+		return
+	}
+	p := new(int)
+	if a, err := f(p, nil); a != p || err != nil {
+		t.Fatalf("unexpected result: %v, %v", a, err)
+	}
+	if a, err := f(nil, nil); a != nil || err == nil || err.Error() != "nil result" {
+		t.Fatalf("unexpected result: %v, %v", a, err)
+	}
+	for _, p := range []*int{p, nil} {
+		if a, err := f(p, io.EOF); a != nil || err != io.EOF {
+			t.Fatalf("unexpected result: %v, %v", a, err)
+		}
+	}
+}
+
+func TestTryOk(t *testing.T) {
+	assert(t, "failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		a = argsToSlice(se.TryOk(1, x, "failed"))
+		return
+	})
+}
+
+func TestTryOk2(t *testing.T) {
+	assert(t, "failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		a = argsToSlice(se.TryOk2(1, 1, x, "failed"))
+		return
+	})
+}
+
+func TestTryOk3(t *testing.T) {
+	assert(t, "failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		a = argsToSlice(se.TryOk3(1, 1, 1, x, "failed"))
+		return
+	})
+}
+
+func TestTryEach(t *testing.T) {
+	var seen []int
+	f := func(items ...int) (err error) {
+		defer se.PassTo(&err)
+		seen = nil
+		se.TryEach(items, func(i int) error {
+			seen = append(seen, i)
+			return errFunc(i >= 0)
+		})
+		return
+	}
+	if err := f(1, 2, 3); err != nil || fmt.Sprint(seen) != "[1 2 3]" {
+		t.Fatalf("unexpected result: %v, %v", err, seen)
+	}
+	err := f(1, -2, 3)
+	if err == nil || err.Error() != "item 1: failed" || fmt.Sprint(seen) != "[1 -2]" {
+		t.Fatalf("unexpected result: %v, %v", err, seen)
+	}
+}
+
+func TestTryMapSlice(t *testing.T) {
+	f := func(items ...int) (a []string, err error) {
+		defer se.PassTo(&err)
+		a = se.TryMapSlice(items, func(i int) (string, error) {
+			return fmt.Sprint(i * 2), errFunc(i >= 0)
+		})
+		return
+	}
+	if a, err := f(); err != nil || a == nil || len(a) != 0 {
+		t.Fatalf("unexpected result: %v, %v", a, err)
+	}
+	if a, err := f(1, 2, 3); err != nil || fmt.Sprint(a) != "[2 4 6]" {
+		t.Fatalf("unexpected result: %v, %v", a, err)
+	}
+	if a, err := f(1, -2, 3); err == nil || err.Error() != "item 1: failed" || a != nil {
+		t.Fatalf("unexpected result: %v, %v", a, err)
+	}
+}
+
+func TestTryReduce(t *testing.T) {
+	f := func(items ...int) (sum int, err error) {
+		defer se.PassTo(&err)
+		sum = se.TryReduce(items, 10, func(acc, i int) (int, error) {
+			return acc + i, errFunc(i >= 0)
+		})
+		return
+	}
+	if sum, err := f(); err != nil || sum != 10 {
+		t.Fatalf("unexpected result: %v, %v", sum, err)
+	}
+	if sum, err := f(1, 2, 3); err != nil || sum != 16 {
+		t.Fatalf("unexpected result: %v, %v", sum, err)
+	}
+	if sum, err := f(1, -2, 3); err == nil || err.Error() != "item 1: failed" || sum != 0 {
+		t.Fatalf("unexpected result: %v, %v", sum, err)
+	}
+}
+
+func TestTryTimeout(t *testing.T) {
+	assert(t, "failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		a = argsToSlice(se.TryTimeout(time.Second, func() (int, error) {
+			return errFunc1(x)
+		}))
+		return
+	})
+	f := func() (a int, err error) {
+		defer se.PassTo(&err)
+		a = se.TryTimeout(time.Millisecond, func() (int, error) {
+			time.Sleep(100 * time.Millisecond)
+			return 1, nil
+		})
+		return
+	}
+	a, err := f()
+	if a != 0 || !errors.Is(err, se.ErrTimeout) || err.Error() != "timeout after 1ms" {
+		t.Fatalf("unexpected result: %d, %v", a, err)
+	}
+	hooked := 0
+	se.OnShortCircuit = func(error) { hooked++ }
+	defer func() { se.OnShortCircuit = nil }()
+	g := func() (a int, err error) {
+		defer se.PassTo(&err)
+		a = se.TryTimeout(time.Second, func() (int, error) {
+			se.Check(io.EOF, "inner")
+			return 1, nil
+		})
+		return
+	}
+	a, err = g()
+	if a != 0 || err == nil || err.Error() != "inner: EOF" || hooked != 1 {
+		t.Fatalf("unexpected result: %d, %v, %d", a, err, hooked)
+	}
+}
+
+func TestTryRetry(t *testing.T) {
+	calls := 0
+	f := func(failures int) (a int, err error) {
+		defer se.PassTo(&err)
+		calls = 0
+		a = se.TryRetry(3, time.Millisecond, func() (int, error) {
+			calls++
+			return errFunc1(calls > failures)
+		})
+		return
+	}
+	if a, err := f(0); a != 1 || err != nil || calls != 1 {
+		t.Fatalf("unexpected result: %d, %v, %d", a, err, calls)
+	}
+	if a, err := f(2); a != 1 || err != nil || calls != 3 {
+		t.Fatalf("unexpected result: %d, %v, %d", a, err, calls)
+	}
+	if a, err := f(3); a != 0 || err == nil || err.Error() != "after 3 attempts: failed" || calls != 3 {
+		t.Fatalf("unexpected result: %d, %v, %d", a, err, calls)
+	}
+}
+
+type exitCodeError int
+
+func (e exitCodeError) Error() string { return "exited" }
+func (e exitCodeError) ExitCode() int { return int(e) }
+
+func TestTryExit(t *testing.T) {
+	f := func(e error) (out []byte, err error) {
+		defer se.PassTo(&err)
+		out = se.TryExit([]byte("out"), e)
+		return
+	}
+	if out, err := f(nil); string(out) != "out" || err != nil {
+		t.Fatalf("unexpected result: %q, %v", out, err)
+	}
+	if _, err := f(io.EOF); err != io.EOF {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := f(exitCodeError(2)); err == nil || err.Error() != "exit code 2: exited" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	exitErr := &exec.ExitError{Stderr: []byte("boom\n")}
+	want := fmt.Sprintf("exit code -1, stderr \"boom\": %v", exitErr)
+	if _, err := f(exitErr); err == nil || err.Error() != want {
+		t.Fatalf("expected: %s got: %v", want, err)
+	}
+}
+
+type testScanner struct {
+	err error
+}
+
+func (s testScanner) Scan(dest ...any) error {
+	for _, d := range dest {
+		*d.(*int) = 1
+	}
+	return s.err
+}
+
+func TestTryScan(t *testing.T) {
+	assert(t, "scan: failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		se.TryScan(errFunc(x))
+		return
+	})
+}
+
+func TestScanInto(t *testing.T) {
+	assert(t, "scan: failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		var i, j int
+		se.ScanInto(testScanner{errFunc(x)}, &i, &j)
+		a = argsToSlice(i, j)
+		return
+	})
+}
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) {
+	return 0, io.ErrClosedPipe
+}
+
+func TestReadFile(t *testing.T) {
+	f := func(path string) (data []byte, err error) {
+		defer se.PassTo(&err)
+		data = se.ReadFile(path)
+		return
+	}
+	if data, err := f("go.mod"); len(data) == 0 || err != nil {
+		t.Fatalf("unexpected result: %q, %v", data, err)
+	}
+	data, err := f("does-not-exist")
+	if data != nil || !errors.Is(err, os.ErrNotExist) || !strings.HasPrefix(err.Error(), "reading file: ") {
+		t.Fatalf("unexpected result: %q, %v", data, err)
+	}
+}
+
+func TestOpenFile(t *testing.T) {
+	f := func(path string) (file *os.File, err error) {
+		defer se.PassTo(&err)
+		file = se.OpenFile(path)
+		return
+	}
+	file, err := f("go.mod")
+	if file == nil || err != nil {
+		t.Fatalf("unexpected result: %v, %v", file, err)
+	}
+	file.Close()
+	file, err = f("does-not-exist")
+	if file != nil || !errors.Is(err, os.ErrNotExist) || !strings.HasPrefix(err.Error(), "opening file: ") {
+		t.Fatalf("unexpected result: %v, %v", file, err)
+	}
+}
+
+func TestReadAll(t *testing.T) {
+	f := func(r io.Reader) (data []byte, err error) {
+		defer se.PassTo(&err)
+		data = se.ReadAll(r)
+		return
+	}
+	if data, err := f(strings.NewReader("data")); string(data) != "data" || err != nil {
+		t.Fatalf("unexpected result: %q, %v", data, err)
+	}
+	if data, err := f(errReader{}); data != nil || err == nil || err.Error() != "reading data: io: read/write on closed pipe" {
+		t.Fatalf("unexpected result: %q, %v", data, err)
+	}
+}
+
+func TestTryParse(t *testing.T) {
+	f := func(parse func(string) any, s string) (v any, err error) {
+		defer se.PassTo(&err)
+		v = parse(s)
+		return
+	}
+	parsers := []struct {
+		parse        func(string) any
+		valid, want  string
+		invalid, msg string
+	}{
+		{func(s string) any { return se.TryAtoi(s) }, "42", "42", "4x", "invalid integer"},
+		{func(s string) any { return se.TryParseFloat(s, 64) }, "1.5", "1.5", "1.x", "invalid float"},
+		{func(s string) any { return se.TryParseBool(s) }, "true", "true", "yes", "invalid bool"},
+	}
+	for _, p := range parsers {
+		if v, err := f(p.parse, p.valid); fmt.Sprint(v) != p.want || err != nil {
+			t.Fatalf("unexpected result: %v, %v", v, err)
+		}
+		var numErr *strconv.NumError
+		v, err := f(p.parse, p.invalid)
+		if v != nil || !errors.As(err, &numErr) || !strings.HasPrefix(err.Error(), fmt.Sprintf("%s %q: ", p.msg, p.invalid)) {
+			t.Fatalf("unexpected result: %v, %v", v, err)
+		}
+	}
+}
+
+func TestMust(t *testing.T) {
+	if a := se.Must(errFunc1(true)); a != 1 {
+		t.Fatalf("expected: 1 got: %d", a)
+	}
+	f := func() (err error) {
+		defer se.PassTo(&err)
+		se.Must(errFunc1(false))
+		return
+	}
+	panicked := false
+	func() {
+		defer func() {
+			e, ok := recover().(error)
+			panicked = ok && e.Error() == "failed"
+		}()
+		f()
+	}()
+	if !panicked {
+		t.Fatal("Expected panic")
+	}
+}
+
+func TestTry2(t *testing.T) {
+	assert(t, "failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		a = argsToSlice(se.Try2(errFunc2(x)))
+		return
+	})
+}
+
+func TestTry3(t *testing.T) {
+	assert(t, "failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		a = argsToSlice(se.Try3(errFunc3(x)))
+		return
+	})
+}
+
+func TestTry4(t *testing.T) {
+	assert(t, "failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		a = argsToSlice(se.Try4(errFunc4(x)))
+		return
+	})
+}
+
+func TestTry5(t *testing.T) {
+	assert(t, "failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		a = argsToSlice(se.Try5(errFunc5(x)))
+		return
+	})
+}
+
+func TestTry6(t *testing.T) {
+	assert(t, "failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		a = argsToSlice(se.Try6(errFunc6(x)))
+		return
+	})
+}
+
+func TestTry7(t *testing.T) {
+	assert(t, "failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		a = argsToSlice(se.Try7(errFunc7(x)))
+		return
+	})
+}
+
+func TestTry8(t *testing.T) {
+	assert(t, "failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		a = argsToSlice(se.Try8(errFunc8(x)))
+		return
+	})
+}
+
+func TestTry9(t *testing.T) {
+	assert(t, "failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		a = argsToSlice(se.Try9(errFunc9(x)))
+		return
+	})
+}
+
+func TestTry10(t *testing.T) {
+	assert(t, "failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		a = argsToSlice(se.Try10(errFunc10(x)))
+		return
+	})
+}
+
+func TestDoFunc(t *testing.T) {
+	assert(t, "failed2: failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		a = argsToSlice(se.DoFunc(func() (int, error) { return errFunc1(x) }).Or("failed2"))
+		return
+	})
+}
+
+func TestDoErr(t *testing.T) {
+	assert(t, "failed2: failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		se.DoErr(errFunc(x)).Or("failed2")
+		return
+	})
+}
+
+func TestDo(t *testing.T) {
+	assert(t, "failed2: failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		a = argsToSlice(se.Do(errFunc1(x)).Or("failed2"))
+		return
+	})
+}
+
+func TestDo2(t *testing.T) {
+	assert(t, "failed2: failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		a = argsToSlice(se.Do2(errFunc2(x)).Or("failed2"))
+		return
+	})
+}
+
+func TestDo3(t *testing.T) {
+	assert(t, "failed2: failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		a = argsToSlice(se.Do3(errFunc3(x)).Or("failed2"))
+		return
+	})
+}
+
+func TestDo4(t *testing.T) {
+	assert(t, "failed2: failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		a = argsToSlice(se.Do4(errFunc4(x)).Or("failed2"))
+		return
+	})
+}
+
+func TestDo5(t *testing.T) {
+	assert(t, "failed2: failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		a = argsToSlice(se.Do5(errFunc5(x)).Or("failed2"))
+		return
+	})
+}
+
+func TestDoTry(t *testing.T) {
+	assert(t, "failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		a = argsToSlice(se.Do(errFunc1(x)).Try())
+		return
+	})
+}
+
+func TestDoTryNoWrap(t *testing.T) {
+	f := func() (err error) {
+		defer se.PassTo(&err)
+		se.Do(1, io.EOF).Try()
+		return
+	}
+	if err := f(); err != io.EOF {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestDo2Try(t *testing.T) {
+	assert(t, "failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		a = argsToSlice(se.Do2(errFunc2(x)).Try())
+		return
+	})
+}
+
+func TestDo3Try(t *testing.T) {
+	assert(t, "failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		a = argsToSlice(se.Do3(errFunc3(x)).Try())
+		return
+	})
+}
+
+func TestDo4Try(t *testing.T) {
+	assert(t, "failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		a = argsToSlice(se.Do4(errFunc4(x)).Try())
+		return
+	})
+}
+
+func TestDo5Try(t *testing.T) {
+	assert(t, "failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		a = argsToSlice(se.Do5(errFunc5(x)).Try())
+		return
+	})
+}
+
+func TestDoOrf(t *testing.T) {
+	assert(t, "failed 2: failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		a = argsToSlice(se.Do(errFunc1(x)).Orf("failed %d", 2))
+		return
+	})
+}
+
+func TestDo2Orf(t *testing.T) {
+	assert(t, "failed 2: failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		a = argsToSlice(se.Do2(errFunc2(x)).Orf("failed %d", 2))
+		return
+	})
+}
+
+func TestDo3Orf(t *testing.T) {
+	assert(t, "failed 2: failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		a = argsToSlice(se.Do3(errFunc3(x)).Orf("failed %d", 2))
+		return
+	})
+}
+
+func TestDo4Orf(t *testing.T) {
+	assert(t, "failed 2: failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		a = argsToSlice(se.Do4(errFunc4(x)).Orf("failed %d", 2))
+		return
+	})
+}
+
+func TestDo5Orf(t *testing.T) {
+	assert(t, "failed 2: failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		a = argsToSlice(se.Do5(errFunc5(x)).Orf("failed %d", 2))
+		return
+	})
+}
+
+func TestOrDefault(t *testing.T) {
+	for _, x := range []bool{true, false} {
+		want := 1
+		if !x {
+			want = 2
+		}
+		results := [][]int{
+			argsToSlice(se.Do(errFunc1(x)).OrDefault(2)),
+			argsToSlice(se.Do2(errFunc2(x)).OrDefault(2, 2)),
+			argsToSlice(se.Do3(errFunc3(x)).OrDefault(2, 2, 2)),
+			argsToSlice(se.Do4(errFunc4(x)).OrDefault(2, 2, 2, 2)),
+			argsToSlice(se.Do5(errFunc5(x)).OrDefault(2, 2, 2, 2, 2)),
+		}
+		for i, a := range results {
+			if !all(a, want) {
+				t.Fatalf("Do%d: expected %d got %v", i+1, want, a)
+			}
+		}
+	}
+}
+
+func TestOrElse(t *testing.T) {
+	var got []error
+	f := func(err error) int {
+		got = append(got, err)
+		return 2
+	}
+	if a := se.Do(errFunc1(true)).OrElse(f); a != 1 || len(got) != 0 {
+		t.Fatalf("unexpected result: %d, %v", a, got)
+	}
+	if a := se.Do(0, io.EOF).OrElse(f); a != 2 || len(got) != 1 || got[0] != io.EOF {
+		t.Fatalf("unexpected result: %d, %v", a, got)
+	}
+}
+
+func TestOrZero(t *testing.T) {
+	for _, x := range []bool{true, false} {
+		want, err := 0, errFunc(false)
+		if x {
+			want, err = 1, nil
+		}
+		results := [][]int{
+			argsToSlice(se.Do(1, err).OrZero()),
+			argsToSlice(se.Do2(1, 1, err).OrZero()),
+			argsToSlice(se.Do3(1, 1, 1, err).OrZero()),
+			argsToSlice(se.Do4(1, 1, 1, 1, err).OrZero()),
+			argsToSlice(se.Do5(1, 1, 1, 1, 1, err).OrZero()),
+		}
+		for i, a := range results {
+			if !all(a, want) {
+				t.Fatalf("Do%d: expected %d got %v", i+1, want, a)
+			}
+		}
+	}
+}
+
+func TestOrLog(t *testing.T) {
+	var buf strings.Builder
+	logger := log.New(&buf, "", 0)
+	if a := se.Do(errFunc1(true)).OrLog(logger, "failed2"); a != 1 || buf.Len() != 0 {
+		t.Fatalf("unexpected result: %d, %q", a, buf.String())
+	}
+	if a := se.Do(1, errFunc(false)).OrLog(logger, "failed2"); a != 0 || buf.String() != "failed2: failed\n" {
+		t.Fatalf("unexpected result: %d, %q", a, buf.String())
+	}
+}
+
+func TestOrContinue(t *testing.T) {
+	for _, x := range []bool{true, false} {
+		want, err := 0, errFunc(false)
+		if x {
+			want, err = 1, nil
+		}
+		var oks []bool
+		collect := func(ok bool) { oks = append(oks, ok) }
+		var a []int
+		a1, ok := se.Do(1, err).OrContinue()
+		collect(ok)
+		a = append(a, a1)
+		a1, a2, ok := se.Do2(1, 1, err).OrContinue()
+		collect(ok)
+		a = append(a, a1, a2)
+		a1, a2, a3, ok := se.Do3(1, 1, 1, err).OrContinue()
+		collect(ok)
+		a = append(a, a1, a2, a3)
+		a1, a2, a3, a4, ok := se.Do4(1, 1, 1, 1, err).OrContinue()
+		collect(ok)
+		a = append(a, a1, a2, a3, a4)
+		a1, a2, a3, a4, a5, ok := se.Do5(1, 1, 1, 1, 1, err).OrContinue()
+		collect(ok)
+		a = append(a, a1, a2, a3, a4, a5)
+		if !all(a, want) {
+			t.Fatalf("expected %d got %v", want, a)
+		}
+		for i, ok := range oks {
+			if ok != x {
+				t.Fatalf("Do%d: expected %v got %v", i+1, x, ok)
+			}
+		}
+	}
+}
+
+func TestOrReturn(t *testing.T) {
+	assert(t, "failed2: failed", func(x bool) (a []int, err error) {
+		v, ok := se.Do(errFunc1(x)).OrReturn(&err, "failed2")
+		if ok != x {
+			t.Fatalf("expected %v got %v", x, ok)
+		}
+		if !ok {
+			return
+		}
+		a = argsToSlice(v)
+		return
+	})
+}
+
+func TestErr(t *testing.T) {
+	for _, x := range []bool{true, false} {
+		want := errFunc(x)
+		errs := []error{
+			se.Do(errFunc1(x)).Err(),
+			se.Do2(errFunc2(x)).Err(),
+			se.Do3(errFunc3(x)).Err(),
+			se.Do4(errFunc4(x)).Err(),
+			se.Do5(errFunc5(x)).Err(),
+		}
+		for i, err := range errs {
+			if (err == nil) != (want == nil) || err != nil && err.Error() != want.Error() {
+				t.Fatalf("Do%d: expected %v got %v", i+1, want, err)
+			}
+		}
+	}
+}
+
+func TestUnwrap(t *testing.T) {
+	results := [][]any{
+		argsToAnySlice(se.Do(1, io.EOF).Unwrap()),
+		argsToAnySlice(se.Do2(1, 2, io.EOF).Unwrap()),
+		argsToAnySlice(se.Do3(1, 2, 3, io.EOF).Unwrap()),
+		argsToAnySlice(se.Do4(1, 2, 3, 4, io.EOF).Unwrap()),
+		argsToAnySlice(se.Do5(1, 2, 3, 4, 5, io.EOF).Unwrap()),
+	}
+	for i, a := range results {
+		for j, v := range a[:len(a)-1] {
+			if v != j+1 {
+				t.Fatalf("Do%d: unexpected values: %v", i+1, a)
+			}
+		}
+		if a[len(a)-1] != io.EOF {
+			t.Fatalf("Do%d: unexpected error: %v", i+1, a[len(a)-1])
+		}
+	}
+}
+
+func TestOrPanic(t *testing.T) {
+	for _, x := range []bool{true, false} {
+		f := func() (a []int, err error) {
+			defer se.PassTo(&err)
+			a = argsToSlice(se.Do(errFunc1(x)).OrPanic("failed2"))
+			a = append(a, argsToSlice(se.Do2(errFunc2(x)).OrPanic("failed2"))...)
+			a = append(a, argsToSlice(se.Do3(errFunc3(x)).OrPanic("failed2"))...)
+			a = append(a, argsToSlice(se.Do4(errFunc4(x)).OrPanic("failed2"))...)
+			a = append(a, argsToSlice(se.Do5(errFunc5(x)).OrPanic("failed2"))...)
+			return
+		}
+		var a []int
+		v := recovered(func() { a, _ = f() })
+		if x && (v != nil || len(a) != 15 || !all(a, 1)) {
+			t.Fatalf("unexpected result: %v, %v", v, a)
+		}
+		if e, ok := v.(error); !x && (!ok || e.Error() != "failed2: failed") {
+			t.Fatalf("unexpected panic: %v", v)
+		}
+	}
+}
+
+func TestResultMust(t *testing.T) {
+	for _, x := range []bool{true, false} {
+		f := func() (a []int, err error) {
+			defer se.PassTo(&err)
+			a = argsToSlice(se.Do(errFunc1(x)).Must())
+			a = append(a, argsToSlice(se.Do2(errFunc2(x)).Must())...)
+			a = append(a, argsToSlice(se.Do3(errFunc3(x)).Must())...)
+			a = append(a, argsToSlice(se.Do4(errFunc4(x)).Must())...)
+			a = append(a, argsToSlice(se.Do5(errFunc5(x)).Must())...)
+			return
+		}
+		var a []int
+		v := recovered(func() { a, _ = f() })
+		if x && (v != nil || len(a) != 15 || !all(a, 1)) {
+			t.Fatalf("unexpected result: %v, %v", v, a)
+		}
+		if e, ok := v.(error); !x && (!ok || e.Error() != "failed") {
+			t.Fatalf("unexpected panic: %v", v)
+		}
+	}
+	if v := recovered(func() { se.Do(1, io.EOF).Must() }); v != io.EOF {
+		t.Fatalf("unexpected panic: %v", v)
+	}
+}
+
+func TestTap(t *testing.T) {
+	var tapped []int
+	assert(t, "failed2: failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		a = argsToSlice(se.Do(errFunc1(x)).Tap(func(a int) { tapped = append(tapped, a) }).Or("failed2"))
+		return
+	})
+	if fmt.Sprint(tapped) != "[1]" {
+		t.Fatalf("unexpected calls: %v", tapped)
+	}
+}
+
+func TestTapErr(t *testing.T) {
+	var tapped []error
+	assert(t, "failed2: failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		a = argsToSlice(se.Do(errFunc1(x)).TapErr(func(err error) { tapped = append(tapped, err) }).Or("failed2"))
+		return
+	})
+	if fmt.Sprint(tapped) != "[failed]" {
+		t.Fatalf("unexpected calls: %v", tapped)
+	}
+}
+
+func TestContext(t *testing.T) {
+	assert(t, "final: layer2: layer1: failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		a = argsToSlice(se.Do(errFunc1(x)).Context("layer1").Context("layer2").Or("final"))
+		return
+	})
+}
+
+func TestFilter(t *testing.T) {
+	called := 0
+	positive := func(a int) bool {
+		called++
+		return a > 0
+	}
+	if err := se.Do(1, nil).Filter(positive, "not positive").Err(); err != nil || called != 1 {
+		t.Fatalf("unexpected result: %v, %d", err, called)
+	}
+	if err := se.Do(-1, nil).Filter(positive, "not positive").Err(); err == nil || err.Error() != "not positive" || called != 2 {
+		t.Fatalf("unexpected result: %v, %d", err, called)
+	}
+	if err := se.Do(-1, io.EOF).Filter(positive, "not positive").Err(); err != io.EOF || called != 2 {
+		t.Fatalf("unexpected result: %v, %d", err, called)
+	}
+}
+
+func TestMap(t *testing.T) {
+	called := false
+	double := func(a int) int {
+		called = true
+		return a * 2
+	}
+	assert(t, "failed2: failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		a = argsToSlice(se.Map(se.Do(errFunc1(x)), double).Or("failed2") / 2)
+		return
+	})
+	called = false
+	se.Map(se.Do(errFunc1(false)), double)
+	if called {
+		t.Fatal("Expected f not to be called")
+	}
+}
+
+func TestAndThen(t *testing.T) {
+	step := func(fail bool) func(int) (int, error) {
+		return func(a int) (int, error) {
+			if fail {
+				return 0, fmt.Errorf("step failed")
+			}
+			return a + 1, nil
+		}
+	}
+	f := func(fail1, fail2, fail3 bool) (a int, err error) {
+		defer se.PassTo(&err)
+		r := se.Do(step(fail1)(0))
+		a = se.AndThen(se.AndThen(r, step(fail2)), step(fail3)).Or("pipeline")
+		return
+	}
+	if a, err := f(false, false, false); a != 3 || err != nil {
+		t.Fatalf("unexpected result: %d, %v", a, err)
+	}
+	for _, fails := range [][3]bool{{true, false, false}, {false, true, false}, {false, false, true}} {
+		a, err := f(fails[0], fails[1], fails[2])
+		if a != 0 || err == nil || err.Error() != "pipeline: step failed" {
+			t.Fatalf("%v: unexpected result: %d, %v", fails, a, err)
+		}
+	}
+}
+
+func TestAssertNoErr(t *testing.T) {
+	assert(t, "failed2: failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		se.AssertNoErr(errFunc(x), "failed2")
+		return
+	})
+}
+
+func TestFlatten(t *testing.T) {
+	if a, err := se.Flatten(se.Do(se.Do(1, nil), nil)).Unwrap(); a != 1 || err != nil {
+		t.Fatalf("unexpected result: %v, %v", a, err)
+	}
+	if a, err := se.Flatten(se.Do(se.Do(1, io.EOF), nil)).Unwrap(); a != 1 || err != io.EOF {
+		t.Fatalf("unexpected result: %v, %v", a, err)
+	}
+	if a, err := se.Flatten(se.Do(se.Do(1, io.EOF), io.ErrClosedPipe)).Unwrap(); a != 0 || err != io.ErrClosedPipe {
+		t.Fatalf("unexpected result: %v, %v", a, err)
+	}
+	if a, err := se.Flatten(se.Do[*se.Result[int]](nil, nil)).Unwrap(); a != 0 || err != se.ErrNilResult {
+		t.Fatalf("unexpected result: %v, %v", a, err)
+	}
+}
+
+func TestZip(t *testing.T) {
+	if a, b, err := se.Zip(se.Do(1, nil), se.Do("b", nil)).Unwrap(); a != 1 || b != "b" || err != nil {
+		t.Fatalf("unexpected result: %v, %v, %v", a, b, err)
+	}
+	if err := se.Zip(se.Do(1, io.EOF), se.Do("b", io.ErrClosedPipe)).Err(); err != io.EOF {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := se.Zip(se.Do(1, nil), se.Do("b", io.ErrClosedPipe)).Err(); err != io.ErrClosedPipe {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestAssert(t *testing.T) {
+	assert(t, "failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		se.Assert(x, "failed")
+		return
+	})
+}
+
+func TestAssertf(t *testing.T) {
+	assert(t, "failed 2 of 3", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		se.Assertf(x, "failed %d of %d", 2, 3)
+		return
+	})
+}
+
+func TestOnShortCircuit(t *testing.T) {
+	var hooked []string
+	se.OnShortCircuit = func(err error) {
+		hooked = append(hooked, err.Error())
+	}
+	defer func() { se.OnShortCircuit = nil }()
+	assert(t, "failed2: failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		a = argsToSlice(se.Do(errFunc1(x)).Or("failed2"))
+		return
+	})
+	if len(hooked) != 1 || hooked[0] != "failed2: failed" {
+		t.Fatalf("unexpected hook calls: %q", hooked)
+	}
+}
+
+func TestAssertLazy(t *testing.T) {
+	called := 0
+	assert(t, "failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		se.AssertLazy(x, func() string {
+			called++
+			return "failed"
+		})
+		return
+	})
+	if called != 1 {
+		t.Fatalf("expected 1 call, got %d", called)
+	}
+}
+
+func TestAssertEqual(t *testing.T) {
+	f := func(got int) (err error) {
+		defer se.PassTo(&err)
+		se.AssertEqual(got, 1, "mismatch")
+		return
+	}
+	if err := f(1); err != nil {
+		t.Fatal("Expected no error")
+	}
+	if err := f(2); err == nil || err.Error() != "mismatch: got 2, want 1" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestAssertNotEqual(t *testing.T) {
+	f := func(got int) (err error) {
+		defer se.PassTo(&err)
+		se.AssertNotEqual(got, 1, "match")
+		return
+	}
+	if err := f(2); err != nil {
+		t.Fatal("Expected no error")
+	}
+	if err := f(1); err == nil || err.Error() != "match: got 1, want anything else" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestAssertGreater(t *testing.T) {
+	f := func(v int) (err error) {
+		defer se.PassTo(&err)
+		se.AssertGreater(v, 1, "too small")
+		return
+	}
+	if err := f(2); err != nil {
+		t.Fatal("Expected no error")
+	}
+	if err := f(1); err == nil || err.Error() != "too small: got 1, want greater than 1" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestAssertLess(t *testing.T) {
+	f := func(v int) (err error) {
+		defer se.PassTo(&err)
+		se.AssertLess(v, 1, "too big")
+		return
+	}
+	if err := f(0); err != nil {
+		t.Fatal("Expected no error")
+	}
+	if err := f(1); err == nil || err.Error() != "too big: got 1, want less than 1" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestAssertInRange(t *testing.T) {
+	f := func(v int) (err error) {
+		defer se.PassTo(&err)
+		se.AssertInRange(v, 1, 3, "out of range")
+		return
+	}
+	for _, v := range []int{1, 2, 3} {
+		if err := f(v); err != nil {
+			t.Fatalf("%d: expected no error", v)
+		}
+	}
+	for _, v := range []int{0, 4} {
+		want := fmt.Sprintf("out of range: got %d, want in range [1, 3]", v)
+		if err := f(v); err == nil || err.Error() != want {
+			t.Fatalf("expected: %s got: %v", want, err)
+		}
+	}
+}
+
+func TestAssertNotEmpty(t *testing.T) {
+	assert(t, "empty", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		s := []int{}
+		if x {
+			s = append(s, 1)
+		}
+		se.AssertNotEmpty(s, "empty")
+		return
+	})
+	f := func() (err error) {
+		defer se.PassTo(&err)
+		se.AssertNotEmpty([]int(nil), "empty")
+		return
+	}
+	if err := f(); err == nil {
+		t.Fatal("Expected error")
+	}
+}
+
+func TestAssertNotBlank(t *testing.T) {
+	f := func(s string) (err error) {
+		defer se.PassTo(&err)
+		se.AssertNotBlank(s, "blank")
+		return
+	}
+	for _, s := range []string{"", " \t\n"} {
+		if err := f(s); err == nil || err.Error() != "blank" {
+			t.Fatalf("%q: unexpected error: %v", s, err)
+		}
+	}
+	if err := f(" x "); err != nil {
+		t.Fatal("Expected no error")
+	}
+}
+
+func TestAssertNotNil(t *testing.T) {
+	f := func(v any) (err error) {
+		defer se.PassTo(&err)
+		se.AssertNotNil(v, "nil value")
+		return
+	}
+	var p *int
+	for _, v := range []any{nil, p, []int(nil)} {
+		if err := f(v); err == nil || err.Error() != "nil value" {
+			t.Fatalf("%#v: expected: nil value got: %v", v, err)
+		}
+	}
+	for _, v := range []any{new(int), 0, ""} {
+		if err := f(v); err != nil {
+			t.Fatalf("%#v: expected no error got: %v", v, err)
+		}
+	}
+}
+
+type testHandler struct {
+	records []slog.Record
+}
+
+func (h *testHandler) Enabled(context.Context, slog.Level) bool { return true }
+func (h *testHandler) WithAttrs([]slog.Attr) slog.Handler       { return h }
+func (h *testHandler) WithGroup(string) slog.Handler            { return h }
+
+func (h *testHandler) Handle(_ context.Context, r slog.Record) error {
+	h.records = append(h.records, r)
+	return nil
+}
+
+func TestOnShortCircuitSlog(t *testing.T) {
+	h := &testHandler{}
+	se.OnShortCircuitSlog = slog.New(h)
+	se.ShortCircuitSlogLevel = slog.LevelWarn
+	defer func() {
+		se.OnShortCircuitSlog = nil
+		se.ShortCircuitSlogLevel = slog.LevelDebug
+	}()
+	assert(t, "failed2: failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		se.Check(errFunc(x), "failed2")
+		return
+	})
+	if len(h.records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(h.records))
+	}
+	r := h.records[0]
+	var attr slog.Attr
+	r.Attrs(func(a slog.Attr) bool {
+		attr = a
+		return false
+	})
+	if r.Level != slog.LevelWarn || attr.Key != "error" || attr.Value.String() != "failed2: failed" {
+		t.Fatalf("unexpected record: %v %v", r, attr)
+	}
+}
+
+type testMetrics map[string]int
+
+func (m testMetrics) IncShortCircuit(label string) {
+	m[label]++
+}
+
+func TestSetMetrics(t *testing.T) {
+	m := testMetrics{}
+	se.SetMetrics(m)
+	defer se.SetMetrics(nil)
+	f := func(x bool) (err error) {
+		defer se.PassTo(&err)
+		se.Check(errFunc(x))
+		return
+	}
+	f(true)
+	f(false)
+	f(false)
+	if len(m) != 1 {
+		t.Fatalf("unexpected metrics: %v", m)
+	}
+	for label, n := range m {
+		if !regexp.MustCompile(`^github\.com/ansiwen/shorterr_test\.TestSetMetrics\.func1:\d+$`).MatchString(label) || n != 2 {
+			t.Fatalf("unexpected metrics: %v", m)
+		}
+	}
+}
+
+func TestRegisterMapper(t *testing.T) {
+	errNotFound := errors.New("not found")
+	unregister := se.RegisterMapper(
+		func(err error) bool { return errors.Is(err, io.ErrUnexpectedEOF) },
+		func(err error) error { return errNotFound })
+	t.Cleanup(unregister)
+	t.Cleanup(se.RegisterMapper(
+		func(err error) bool { return errors.Is(err, io.ErrUnexpectedEOF) },
+		func(err error) error { return io.ErrClosedPipe }))
+	f := func(e error) (err error) {
+		defer se.PassTo(&err)
+		se.Check(e, "ctx")
+		return
+	}
+	if err := f(io.ErrUnexpectedEOF); err != errNotFound {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := f(io.EOF); err == nil || err.Error() != "ctx: EOF" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	unregister()
+	if err := f(io.ErrUnexpectedEOF); err != io.ErrClosedPipe {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(se.RegisterMapper(
+		func(err error) bool { return errors.Is(err, io.ErrNoProgress) },
+		func(err error) error { return nil }))
+	v := recovered(func() { f(io.ErrNoProgress) })
+	if e, ok := v.(error); !ok || e.Error() != "shorterr: mapper returned nil" {
+		t.Fatalf("unexpected panic: %v", v)
+	}
+}
+
+func TestCaptureStack(t *testing.T) {
+	se.CaptureStack = true
+	defer func() { se.CaptureStack = false }()
+	assert(t, "failed2: failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		a = argsToSlice(se.Do(errFunc1(x)).Or("failed2"))
+		return
+	})
+	f := func() (err error) {
+		defer se.PassTo(&err)
+		se.Check(io.EOF)
+		return
+	}
+	err := f()
+	var st interface{ StackTrace() []uintptr }
+	if !errors.As(err, &st) || len(st.StackTrace()) == 0 {
+		t.Fatal("Expected stack trace")
+	}
+	if !errors.Is(err, io.EOF) || err.Error() != "EOF" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s := fmt.Sprintf("%+v", err)
+	if !strings.HasPrefix(s, "EOF\ngithub.com/ansiwen/shorterr_test.TestCaptureStack.func") {
+		t.Fatalf("unexpected stack trace: %s", s)
+	}
+}
+
+func TestIncludeCaller(t *testing.T) {
+	se.IncludeCaller = true
+	defer func() { se.IncludeCaller = false }()
+	f := func() (err error) {
+		defer se.PassTo(&err)
+		se.Do(errFunc1(false)).Or("failed2")
+		return
+	}
+	err := f()
+	if err == nil || !regexp.MustCompile(`^shorterr_test\.go:\d+: failed2: failed$`).MatchString(err.Error()) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestOtherPanic(t *testing.T) {
+	f := func() (err error) {
+		defer se.PassTo(&err)
+		panic("bla")
+	}
+	panicked := false
+	func() {
+		defer func() {
+			s, ok := recover().(string)
+			panicked = ok && s == "bla"
+		}()
+		f()
+	}()
+	if !panicked {
+		t.Fatal("Expected panic")
+	}
+}
+
+func TestPassToWithOtherPanic(t *testing.T) {
+	f := func() (err error) {
+		defer se.PassToWith(&err, func(err error) error { return err })
+		panic("bla")
+	}
+	panicked := false
+	func() {
+		defer func() {
+			s, ok := recover().(string)
+			panicked = ok && s == "bla"
+		}()
+		f()
+	}()
+	if !panicked {
+		t.Fatal("Expected panic")
+	}
+}
+
+func TestRecoverAllRuntimeError(t *testing.T) {
+	f := func() (err error) {
+		defer se.RecoverAll(&err)
+		var m map[string]int
+		m["x"] = 1
+		return
+	}
+	err := f()
+	var rtErr runtime.Error
+	if !errors.As(err, &rtErr) || !strings.HasPrefix(err.Error(), "panic: ") {
+		t.Fatalf("unexpected error: %v", err)
+	}
 }
 
-func TestTry(t *testing.T) {
+func TestCatch(t *testing.T) {
+	var caught *os.PathError
+	f := func(e error) (err error) {
+		defer se.Catch(&err, func(e *os.PathError) { caught = e })
+		se.Check(e, "wrapped")
+		return
+	}
+	_, pathErr := os.Open("does-not-exist")
+	if err := f(pathErr); err != nil || caught == nil {
+		t.Fatalf("Expected caught error, got: %v", err)
+	}
+	caught = nil
+	if err := f(io.EOF); !errors.Is(err, io.EOF) || caught != nil {
+		t.Fatalf("Expected uncaught error, got: %v", err)
+	}
+	if err := f(nil); err != nil || caught != nil {
+		t.Fatal("Expected no error")
+	}
+}
+
+func TestRecoverAll(t *testing.T) {
 	assert(t, "failed", func(x bool) (a []int, err error) {
-		defer se.PassTo(&err)
+		defer se.RecoverAll(&err)
 		a = argsToSlice(se.Try(errFunc1(x)))
 		return
 	})
+	f := func() (err error) {
+		defer se.RecoverAll(&err)
+		panic("bla")
+	}
+	if err := f(); err == nil || err.Error() != "panic: bla" {
+		t.Fatalf("unexpected error: %v", err)
+	}
 }
 
-func TestTry2(t *testing.T) {
-	assert(t, "failed", func(x bool) (a []int, err error) {
+func TestCollector(t *testing.T) {
+	var c se.Collector
+	if c.Result() != nil {
+		t.Fatal("Expected no error")
+	}
+	c.Add(nil)
+	c.Assert(true, "ok")
+	if c.Result() != nil {
+		t.Fatal("Expected no error")
+	}
+	c.Add(io.EOF)
+	c.Assert(false, "failed")
+	err := c.Result()
+	if !errors.Is(err, io.EOF) || err.Error() != "EOF\nfailed" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	var r se.Recorder
+	r.Check(nil, "skipped")
+	r.Check(io.EOF, "first")
+	r.Check(nil)
+	r.Check(io.ErrClosedPipe)
+	errs := r.Errors()
+	if len(errs) != 2 || errs[0].Error() != "first: EOF" || !errors.Is(errs[0], io.EOF) || errs[1] != io.ErrClosedPipe {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+}
+
+type testCloser struct {
+	closed *[]int
+	id     int
+}
+
+func (c testCloser) Close() error {
+	*c.closed = append(*c.closed, c.id)
+	return nil
+}
+
+func TestTryClose(t *testing.T) {
+	var closed []int
+	open := func(id int, fail bool) (testCloser, error) {
+		return testCloser{&closed, id}, errFunc(!fail)
+	}
+	f := func(failOpen, failLater bool) (err error) {
+		var c se.Cleanups
+		defer c.Run()
 		defer se.PassTo(&err)
-		a = argsToSlice(se.Try2(errFunc2(x)))
+		se.TryClose(open(1, false)).DeferTo(&c)
+		se.TryClose(open(2, failOpen)).DeferTo(&c)
+		se.Assert(!failLater, "failed later")
+		return
+	}
+	if err := f(false, false); err != nil || fmt.Sprint(closed) != "[2 1]" {
+		t.Fatalf("unexpected result: %v, %v", err, closed)
+	}
+	closed = nil
+	if err := f(false, true); err == nil || fmt.Sprint(closed) != "[2 1]" {
+		t.Fatalf("unexpected result: %v, %v", err, closed)
+	}
+	closed = nil
+	if err := f(true, false); err == nil || fmt.Sprint(closed) != "[1]" {
+		t.Fatalf("unexpected result: %v, %v", err, closed)
+	}
+}
+
+func TestScope(t *testing.T) {
+	var order []int
+	f := func(x bool) (a []int, err error) {
+		s := se.NewScope(&err)
+		defer s.Done()
+		order = nil
+		s.Defer(func() { order = append(order, 1) })
+		s.Defer(func() { order = append(order, 2) })
+		a = argsToSlice(se.Try(errFunc1(x)))
 		return
+	}
+	assert(t, "failed", f)
+	if fmt.Sprint(order) != "[2 1]" {
+		t.Fatalf("unexpected order: %v", order)
+	}
+	f(true)
+	if fmt.Sprint(order) != "[2 1]" {
+		t.Fatalf("unexpected order: %v", order)
+	}
+}
+
+func TestWithRecover(t *testing.T) {
+	if err := se.WithRecover(func() error { return nil })(); err != nil {
+		t.Fatal("Expected no error")
+	}
+	err := se.WithRecover(func() error {
+		se.Try(errFunc1(false))
+		return nil
+	})()
+	if err == nil || err.Error() != "failed" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	f := se.WithRecover(func() error { panic("bla") })
+	if v := recovered(func() { f() }); v != "bla" {
+		t.Fatalf("unexpected panic: %v", v)
+	}
+}
+
+func TestTryBool(t *testing.T) {
+	if !se.TryBool(func() { se.Try(errFunc1(true)) }) {
+		t.Fatal("Expected true")
+	}
+	if se.TryBool(func() { se.Try(errFunc1(false)) }) {
+		t.Fatal("Expected false")
+	}
+	if v := recovered(func() { se.TryBool(func() { panic("bla") }) }); v != "bla" {
+		t.Fatalf("unexpected panic: %v", v)
+	}
+}
+
+type testT struct {
+	failures []string
+}
+
+func (t *testT) Helper() {}
+
+func (t *testT) Fatalf(format string, args ...any) {
+	t.failures = append(t.failures, fmt.Sprintf(format, args...))
+}
+
+func TestMustNotShortCircuit(t *testing.T) {
+	se.MustNotShortCircuit(t, func() { se.Check(nil) })
+	tt := &testT{}
+	se.MustNotShortCircuit(tt, func() { se.Check(errFunc(false)) })
+	if len(tt.failures) != 1 || tt.failures[0] != "unexpected short-circuit: failed" {
+		t.Fatalf("unexpected failures: %q", tt.failures)
+	}
+}
+
+func TestRequireNoError(t *testing.T) {
+	tt := &testT{}
+	se.RequireNoError(tt, nil, "failed2")
+	se.RequireNoError(tt, errFunc(false), "failed2")
+	if len(tt.failures) != 1 || tt.failures[0] != "failed2: failed" {
+		t.Fatalf("unexpected failures: %q", tt.failures)
+	}
+}
+
+func TestRequireTrue(t *testing.T) {
+	tt := &testT{}
+	se.RequireTrue(tt, true, "failed")
+	se.RequireTrue(tt, false, "failed")
+	if len(tt.failures) != 1 || tt.failures[0] != "failed" {
+		t.Fatalf("unexpected failures: %q", tt.failures)
+	}
+}
+
+func TestGo(t *testing.T) {
+	if err := <-se.Go(func() error { return nil }); err != nil {
+		t.Fatal("Expected no error")
+	}
+	err := <-se.Go(func() error {
+		se.Try(errFunc1(false))
+		return nil
 	})
+	if err == nil || err.Error() != "failed" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = <-se.Go(func() error { panic("bla") })
+	if err == nil || err.Error() != "panic: bla" {
+		t.Fatalf("unexpected error: %v", err)
+	}
 }
 
-func TestTry3(t *testing.T) {
-	assert(t, "failed", func(x bool) (a []int, err error) {
+func TestGroup(t *testing.T) {
+	var g se.Group
+	for i := 0; i < 10; i++ {
+		i := i
+		g.Go(func() error {
+			se.Assertf(i != 5, "failed %d", i)
+			return nil
+		})
+	}
+	if err := g.Wait(); err == nil || err.Error() != "failed 5" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var g2 se.Group
+	g2.Go(func() error { return nil })
+	if err := g2.Wait(); err != nil {
+		t.Fatal("Expected no error")
+	}
+}
+
+func TestCheckCode(t *testing.T) {
+	assert(t, "failed2: failed", func(x bool) (a []int, err error) {
 		defer se.PassTo(&err)
-		a = argsToSlice(se.Try3(errFunc3(x)))
+		se.CheckCode(errFunc(x), "E42", "failed2")
 		return
 	})
+	f := func() (err error) {
+		defer se.PassTo(&err)
+		se.CheckCode(io.EOF, "E42", "failed2")
+		return
+	}
+	err := f()
+	var codeErr *se.CodeError
+	if !errors.As(err, &codeErr) || codeErr.Code != "E42" || !errors.Is(err, io.EOF) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, _ := json.Marshal(err)
+	if string(data) != `{"code":"E42","message":"failed2"}` {
+		t.Fatalf("unexpected JSON: %s", data)
+	}
 }
 
-func TestTry4(t *testing.T) {
-	assert(t, "failed", func(x bool) (a []int, err error) {
+func TestCheckKV(t *testing.T) {
+	assert(t, "failed2: failed", func(x bool) (a []int, err error) {
 		defer se.PassTo(&err)
-		a = argsToSlice(se.Try4(errFunc4(x)))
+		se.CheckKV(errFunc(x), "failed2", "id", 42)
 		return
 	})
+	f := func() (err error) {
+		defer se.PassTo(&err)
+		se.CheckKV(io.EOF, "failed2", "id", 42, "name", "x")
+		return
+	}
+	err := f()
+	var kvErr *se.KVError
+	if !errors.As(err, &kvErr) || fmt.Sprint(kvErr.Fields()) != "[id 42 name x]" || !errors.Is(err, io.EOF) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s := kvErr.LogValue().String(); s != "[error=failed2: EOF id=42 name=x]" {
+		t.Fatalf("unexpected log value: %s", s)
+	}
+	v := recovered(func() { se.CheckKV(nil, "failed2", "id") })
+	if _, ok := v.(error); !ok {
+		t.Fatalf("unexpected panic: %v", v)
+	}
 }
 
-func TestTry5(t *testing.T) {
+func TestCapture(t *testing.T) {
 	assert(t, "failed", func(x bool) (a []int, err error) {
-		defer se.PassTo(&err)
-		a = argsToSlice(se.Try5(errFunc5(x)))
+		c := se.Capture(&err)
+		defer c.Done()
+		c.Set("op", "load")
+		a = argsToSlice(se.Try(errFunc1(x)))
+		c.Set("late", true)
 		return
 	})
+	f := func() (err error) {
+		c := se.Capture(&err)
+		defer c.Done()
+		c.Set("op", "load")
+		c.Set("id", 1)
+		c.Set("op", "parse")
+		se.Check(io.EOF)
+		c.Set("late", true)
+		return
+	}
+	err := f()
+	var kvErr *se.KVError
+	if !errors.As(err, &kvErr) || fmt.Sprint(kvErr.Fields()) != "[op parse id 1]" || !errors.Is(err, io.EOF) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	g := func() (err error) {
+		c := se.Capture(&err)
+		defer c.Done()
+		se.Check(io.EOF)
+		return
+	}
+	if err := g(); err != io.EOF {
+		t.Fatalf("unexpected error: %v", err)
+	}
 }
 
-func TestDo(t *testing.T) {
-	assert(t, "failed2: failed", func(x bool) (a []int, err error) {
+func TestAssertField(t *testing.T) {
+	assert(t, "name: missing", func(x bool) (a []int, err error) {
 		defer se.PassTo(&err)
-		a = argsToSlice(se.Do(errFunc1(x)).Or("failed2"))
+		se.AssertField(x, "name", "missing")
 		return
 	})
+	f := func() (err error) {
+		defer se.PassTo(&err)
+		se.AssertField(false, "name", "missing")
+		return
+	}
+	var fieldErr *se.FieldError
+	if err := f(); !errors.As(err, &fieldErr) || fieldErr.Field() != "name" || fieldErr.Message() != "missing" {
+		t.Fatalf("unexpected error: %v", err)
+	}
 }
 
-func TestDo2(t *testing.T) {
-	assert(t, "failed2: failed", func(x bool) (a []int, err error) {
+func TestCheckHTTP(t *testing.T) {
+	assert(t, "failed", func(x bool) (a []int, err error) {
 		defer se.PassTo(&err)
-		a = argsToSlice(se.Do2(errFunc2(x)).Or("failed2"))
+		se.CheckHTTP(errFunc(x), http.StatusBadGateway)
 		return
 	})
+	f := func(status int) (err error) {
+		defer se.PassTo(&err)
+		se.CheckHTTP(io.EOF, status)
+		return
+	}
+	err := f(http.StatusBadGateway)
+	var httpErr *se.HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode() != http.StatusBadGateway || !errors.Is(err, io.EOF) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status := se.StatusCode(f(0)); status != http.StatusInternalServerError {
+		t.Fatalf("unexpected status: %d", status)
+	}
+	if status := se.StatusCode(io.EOF); status != http.StatusInternalServerError {
+		t.Fatalf("unexpected status: %d", status)
+	}
 }
 
-func TestDo3(t *testing.T) {
-	assert(t, "failed2: failed", func(x bool) (a []int, err error) {
+func TestAssertHTTP(t *testing.T) {
+	assert(t, "not found", func(x bool) (a []int, err error) {
 		defer se.PassTo(&err)
-		a = argsToSlice(se.Do3(errFunc3(x)).Or("failed2"))
+		se.AssertHTTP(x, http.StatusNotFound, "not found")
 		return
 	})
+	f := func() (err error) {
+		defer se.PassTo(&err)
+		se.AssertHTTP(false, http.StatusNotFound, "not found")
+		return
+	}
+	if status := se.StatusCode(fmt.Errorf("wrapped: %w", f())); status != http.StatusNotFound {
+		t.Fatalf("unexpected status: %d", status)
+	}
 }
 
-func TestDo4(t *testing.T) {
-	assert(t, "failed2: failed", func(x bool) (a []int, err error) {
+func TestMissingPassTo(t *testing.T) {
+	v := recovered(func() { se.Check(io.EOF) })
+	if e, ok := v.(error); !ok || e.Error() != "shorterr: short-circuit without PassTo: EOF" {
+		t.Fatalf("unexpected panic: %v", v)
+	}
+}
+
+func TestChain(t *testing.T) {
+	c := se.Chain("op")
+	assert(t, "op: failed", func(x bool) (a []int, err error) {
 		defer se.PassTo(&err)
-		a = argsToSlice(se.Do4(errFunc4(x)).Or("failed2"))
+		c.Check(errFunc(x))
 		return
 	})
-}
-
-func TestDo5(t *testing.T) {
-	assert(t, "failed2: failed", func(x bool) (a []int, err error) {
+	assert(t, "op: failed2: failed", func(x bool) (a []int, err error) {
 		defer se.PassTo(&err)
-		a = argsToSlice(se.Do5(errFunc5(x)).Or("failed2"))
+		c.Check(errFunc(x), "failed2")
 		return
 	})
-}
-
-func TestAssert(t *testing.T) {
-	assert(t, "failed", func(x bool) (a []int, err error) {
+	assert(t, "op: failed 2: failed", func(x bool) (a []int, err error) {
 		defer se.PassTo(&err)
-		se.Assert(x, "failed")
+		c.Checkf(errFunc(x), "failed %d", 2)
+		return
+	})
+	assert(t, "op: failed", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		c.Assert(x, "failed")
+		return
+	})
+	assert(t, "op: failed 2", func(x bool) (a []int, err error) {
+		defer se.PassTo(&err)
+		c.Assertf(x, "failed %d", 2)
 		return
 	})
 }
 
-func TestOtherPanic(t *testing.T) {
+type foreignPanic struct{ error }
+
+// foreignRecover simulates another library using panic and recover for control
+// flow with its own panic type.
+func foreignRecover(f func()) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			e, ok := v.(foreignPanic)
+			if !ok {
+				panic(v)
+			}
+			err = e.error
+		}
+	}()
+	f()
+	return
+}
+
+func TestForeignPanic(t *testing.T) {
 	f := func() (err error) {
 		defer se.PassTo(&err)
-		panic("bla")
+		err = foreignRecover(func() { se.Check(io.EOF) })
+		return
 	}
-	panicked := false
-	func() {
-		defer func() {
-			s, ok := recover().(string)
-			panicked = ok && s == "bla"
-		}()
-		f()
-	}()
-	if !panicked {
-		t.Fatal("Expected panic")
+	if err := f(); err != io.EOF {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	g := func() (err error) {
+		defer se.PassTo(&err)
+		panic(foreignPanic{io.EOF})
+	}
+	var err error
+	v := recovered(func() { err = foreignRecover(func() { g() }) })
+	if v != nil || err != io.EOF {
+		t.Fatalf("unexpected result: %v, %v", v, err)
+	}
+	v = recovered(func() { se.Check(io.EOF) })
+	if e, ok := v.(error); !ok || !errors.Is(e, io.EOF) {
+		t.Fatalf("unexpected panic: %v", v)
 	}
 }
 
@@ -228,10 +2313,70 @@ func errFunc5(b bool) (int, int, int, int, int, error) {
 	return 1, 1, 1, 1, 1, nil
 }
 
+func errFunc6(b bool) (int, int, int, int, int, int, error) {
+	if !b {
+		return 0, 0, 0, 0, 0, 0, errFunc(false)
+	}
+	return 1, 1, 1, 1, 1, 1, nil
+}
+
+func errFunc7(b bool) (int, int, int, int, int, int, int, error) {
+	if !b {
+		return 0, 0, 0, 0, 0, 0, 0, errFunc(false)
+	}
+	return 1, 1, 1, 1, 1, 1, 1, nil
+}
+
+func errFunc8(b bool) (int, int, int, int, int, int, int, int, error) {
+	if !b {
+		return 0, 0, 0, 0, 0, 0, 0, 0, errFunc(false)
+	}
+	return 1, 1, 1, 1, 1, 1, 1, 1, nil
+}
+
+func errFunc9(b bool) (int, int, int, int, int, int, int, int, int, error) {
+	if !b {
+		return 0, 0, 0, 0, 0, 0, 0, 0, 0, errFunc(false)
+	}
+	return 1, 1, 1, 1, 1, 1, 1, 1, 1, nil
+}
+
+func errFunc10(b bool) (int, int, int, int, int, int, int, int, int, int, error) {
+	if !b {
+		return 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, errFunc(false)
+	}
+	return 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, nil
+}
+
+func errFuncR1(b bool) (error, int) {
+	a, err := errFunc1(b)
+	return err, a
+}
+
+func errFuncR2(b bool) (error, int, int) {
+	a, b2, err := errFunc2(b)
+	return err, a, b2
+}
+
+func errFuncR3(b bool) (error, int, int, int) {
+	a, b2, c, err := errFunc3(b)
+	return err, a, b2, c
+}
+
+func recovered(f func()) (v any) {
+	defer func() { v = recover() }()
+	f()
+	return
+}
+
 func argsToSlice(i ...int) []int {
 	return i
 }
 
+func argsToAnySlice(i ...any) []any {
+	return i
+}
+
 func all(a []int, x int) bool {
 	for _, i := range a {
 		if i != x {
@@ -261,3 +2406,35 @@ func assert(t *testing.T, msg string, f func(x bool) (a []int, err error)) {
 		t.Fatalf("expected: %s got: %s", msg, err.Error())
 	}
 }
+
+func BenchmarkTry(b *testing.B) {
+	f := func() (a int, err error) {
+		defer se.PassTo(&err)
+		a = se.Try(errFunc1(true))
+		return
+	}
+	for i := 0; i < b.N; i++ {
+		f()
+	}
+}
+
+func BenchmarkCheckError(b *testing.B) {
+	f := func() (err error) {
+		defer se.PassTo(&err)
+		se.Check(errFunc(false))
+		return
+	}
+	for i := 0; i < b.N; i++ {
+		f()
+	}
+}
+
+func BenchmarkDoErrError(b *testing.B) {
+	f := func() (int, error) {
+		r := se.Do(errFunc1(false))
+		return r.OrZero(), r.Err()
+	}
+	for i := 0; i < b.N; i++ {
+		f()
+	}
+}